@@ -0,0 +1,294 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// maxPlaceholders is the most parameters MySQL accepts in one prepared statement
+	maxPlaceholders = 65535
+
+	// packetOverhead leaves room for the packet header and statement metadata
+	packetOverhead = 1024
+)
+
+// InsertBatch is one multi-row INSERT statement. With consecutive auto-increment
+// (innodb_autoinc_lock_mode 0 or 1, auto_increment_increment 1) its rows got the ids FirstId to FirstId+Count-1.
+type InsertBatch struct {
+	FirstId int64
+	Count   int64
+}
+
+type BatchInsertResult struct {
+	RowsAffected int64
+	Batches      []InsertBatch
+}
+
+// Ids expands the generated ids of every batch, assuming consecutive auto-increment.
+func (r *BatchInsertResult) Ids() []int64 {
+	var ids []int64
+	for _, batch := range r.Batches {
+		for i := int64(0); i < batch.Count; i++ {
+			ids = append(ids, batch.FirstId+i)
+		}
+	}
+	return ids
+}
+
+// BatchInsert inserts input, a slice of structs or struct pointers, with multi-row INSERT statements
+// inside one transaction. Columns are chosen like InsertStruct, except that autoincr and omitempty fields
+// are left out only when they are zero in every row. Generated ids are written back into autoincr fields.
+func (mc *MysqlClient) BatchInsert(table string, input interface{}) (*BatchInsertResult, error) {
+	return mc.BatchInsertContext(context.Background(), table, input)
+}
+
+func (mc *MysqlClient) BatchInsertContext(ctx context.Context, table string, input interface{}) (*BatchInsertResult, error) {
+	var result *BatchInsertResult
+	err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		result, err = mc.BatchInsertTxContext(ctx, tx, table, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (mc *MysqlClient) BatchInsertTx(tx *sql.Tx, table string, input interface{}) (*BatchInsertResult, error) {
+	return mc.BatchInsertTxContext(context.Background(), tx, table, input)
+}
+
+func (mc *MysqlClient) BatchInsertTxContext(ctx context.Context, tx *sql.Tx, table string, input interface{}) (*BatchInsertResult, error) {
+	sliceV := reflect.ValueOf(input)
+	if sliceV.Kind() == reflect.Ptr {
+		sliceV = sliceV.Elem()
+	}
+	if sliceV.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%v must be a slice of struct or struct pointer", input)
+	}
+	structT := sliceV.Type().Elem()
+	if structT.Kind() == reflect.Ptr {
+		structT = structT.Elem()
+	}
+	if !isMappableStruct(structT) {
+		return nil, fmt.Errorf("%v must be a slice of struct or struct pointer", sliceV.Type())
+	}
+	structs := make([]reflect.Value, sliceV.Len())
+	for i := range structs {
+		elem := sliceV.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil, fmt.Errorf("row %d is nil", i)
+			}
+			elem = elem.Elem()
+		}
+		structs[i] = elem
+	}
+	infos := batchColumns(structT, structs)
+	columns := make([]string, len(infos))
+	for i, info := range infos {
+		columns[i] = info.column
+	}
+	rows := make([][]interface{}, len(structs))
+	for i, structV := range structs {
+		row := make([]interface{}, len(infos))
+		for j, info := range infos {
+			value, err := fieldValue(structV, info)
+			if err != nil {
+				return nil, err
+			}
+			row[j] = value
+		}
+		rows[i] = row
+	}
+	result, err := mc.batchInsert(ctx, tx, table, columns, rows)
+	if err != nil {
+		return nil, err
+	}
+	autoIncrOmitted := true
+	for _, info := range infos {
+		if info.autoIncr {
+			autoIncrOmitted = false
+		}
+	}
+	if autoIncrOmitted {
+		for i, id := range result.Ids() {
+			err = setAutoIncrement(structs[i], id)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// BatchInsertRows inserts rows of positional values for columns, split and wrapped in a transaction like BatchInsert.
+func (mc *MysqlClient) BatchInsertRows(table string, columns []string, rows [][]interface{}) (*BatchInsertResult, error) {
+	return mc.BatchInsertRowsContext(context.Background(), table, columns, rows)
+}
+
+func (mc *MysqlClient) BatchInsertRowsContext(ctx context.Context, table string, columns []string, rows [][]interface{}) (*BatchInsertResult, error) {
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("row %d has %d values for %d columns", i, len(row), len(columns))
+		}
+	}
+	var result *BatchInsertResult
+	err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		result, err = mc.batchInsert(ctx, tx, table, columns, rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func batchColumns(structT reflect.Type, structs []reflect.Value) []fieldInfo {
+	var infos []fieldInfo
+	for _, info := range cachedStructColumns(structT) {
+		if info.autoIncr || info.omitEmpty {
+			allZero := true
+			for _, structV := range structs {
+				field, ok := fieldByIndex(structV, info.index, false)
+				if ok && !field.IsZero() {
+					allZero = false
+					break
+				}
+			}
+			if allZero {
+				continue
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func (mc *MysqlClient) batchInsert(ctx context.Context, e execer, table string, columns []string, rows [][]interface{}) (*BatchInsertResult, error) {
+	result := &BatchInsertResult{}
+	if len(rows) == 0 {
+		return result, nil
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns to insert into %s", table)
+	}
+	encoded := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		args, err := mc.getConverters().encodeArgs(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", i, err)
+		}
+		encoded[i] = args
+	}
+	maxRows, maxPacket := 1000, int64(defaultMaxAllowedPacket)
+	if mc.config != nil {
+		maxRows = mc.config.batchSize
+		if mc.config.maxAllowedPacket > 0 {
+			maxPacket = mc.config.maxAllowedPacket
+		}
+	}
+	ranges, err := splitBatches(encoded, len(insertSQL(table, columns, 1)), maxRows, maxPacket)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range ranges {
+		batch := encoded[r[0]:r[1]]
+		args := make([]interface{}, 0, len(batch)*len(columns))
+		for _, row := range batch {
+			args = append(args, row...)
+		}
+		res, err := e.ExecContext(ctx, insertSQL(table, columns, len(batch)), args...)
+		if err != nil {
+			return nil, err
+		}
+		firstId, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		result.RowsAffected += rowsAffected
+		result.Batches = append(result.Batches, InsertBatch{FirstId: firstId, Count: int64(len(batch))})
+	}
+	return result, nil
+}
+
+// defaultMaxAllowedPacket is the server default, used when max_allowed_packet cannot be read.
+const defaultMaxAllowedPacket = 4194304
+
+// initialMaxAllowedPacket reads the server's max_allowed_packet unless the MaxAllowedPacket option set one.
+func (mc *MysqlClient) initialMaxAllowedPacket(ctx context.Context) {
+	if mc.config.maxAllowedPacket > 0 {
+		return
+	}
+	maxAllowedPacket, err := QueryScalarContext[int64](ctx, mc, "SELECT @@max_allowed_packet")
+	if err != nil {
+		mc.config.logger.Printf("mysqlclient: reading max_allowed_packet: %v", err)
+		mc.config.maxAllowedPacket = defaultMaxAllowedPacket
+		return
+	}
+	mc.config.maxAllowedPacket = maxAllowedPacket
+}
+
+// splitBatches returns [start, end) row ranges so that every statement stays within maxRows rows,
+// maxPlaceholders parameters and an estimated maxPacket bytes. baseLen is the statement length for one row.
+func splitBatches(rows [][]interface{}, baseLen int, maxRows int, maxPacket int64) ([][2]int, error) {
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 && maxPlaceholders/len(rows[0]) < maxRows {
+		maxRows = maxPlaceholders / len(rows[0])
+	}
+	var ranges [][2]int
+	start := 0
+	size := int64(baseLen + packetOverhead)
+	for i, row := range rows {
+		rowSize := estimateRowSize(row)
+		if int64(baseLen+packetOverhead)+rowSize > maxPacket {
+			return nil, fmt.Errorf("row %d is about %d bytes and does not fit max_allowed_packet %d", i, rowSize, maxPacket)
+		}
+		if i > start && (i-start >= maxRows || size+rowSize > maxPacket) {
+			ranges = append(ranges, [2]int{start, i})
+			start = i
+			size = int64(baseLen + packetOverhead)
+		}
+		size += rowSize
+	}
+	if start < len(rows) {
+		ranges = append(ranges, [2]int{start, len(rows)})
+	}
+	return ranges, nil
+}
+
+// estimateRowSize approximates the bytes a row adds to the statement: its placeholder group plus the encoded values.
+func estimateRowSize(row []interface{}) int64 {
+	size := int64(3 * len(row))
+	for _, arg := range row {
+		switch v := arg.(type) {
+		case nil:
+			size += 1
+		case string:
+			size += int64(len(v)) + 9
+		case []byte:
+			size += int64(len(v)) + 9
+		case time.Time:
+			size += 12
+		case bool:
+			size += 1
+		case int64, uint64, float64, int, uint:
+			size += 8
+		default:
+			size += int64(len(fmt.Sprint(v))) + 9
+		}
+	}
+	return size
+}
@@ -0,0 +1,82 @@
+package mysqlclient
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitBatches(t *testing.T) {
+	rows := make([][]interface{}, 10)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i), "test name"}
+	}
+	ranges, err := splitBatches(rows, 50, 4, 4194304)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][2]int{{0, 4}, {4, 8}, {8, 10}}, ranges)
+
+	rowSize := estimateRowSize(rows[0])
+	ranges, err = splitBatches(rows, 50, 1000, 50+packetOverhead+3*rowSize)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][2]int{{0, 3}, {3, 6}, {6, 9}, {9, 10}}, ranges)
+
+	_, err = splitBatches([][]interface{}{{strings.Repeat("x", 2000)}}, 50, 1000, 2048)
+	assert.NotNil(t, err)
+}
+
+func TestSplitBatchesPlaceholderLimit(t *testing.T) {
+	row := make([]interface{}, 1000)
+	rows := make([][]interface{}, 100)
+	for i := range rows {
+		rows[i] = row
+	}
+	ranges, err := splitBatches(rows, 50, 1000, 1<<30)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][2]int{{0, 65}, {65, 100}}, ranges)
+}
+
+func TestBatchColumns(t *testing.T) {
+	users := []reflect.Value{
+		reflect.ValueOf(&crudUser{Name: "a"}).Elem(),
+		reflect.ValueOf(&crudUser{Name: "b", Description: "This is description"}).Elem(),
+	}
+	var columns []string
+	for _, info := range batchColumns(reflect.TypeOf(crudUser{}), users) {
+		columns = append(columns, info.column)
+	}
+	assert.EqualValues(t, []string{"name", "age", "description", "tags", "is_delete"}, columns)
+}
+
+func TestBatchInsertResultIds(t *testing.T) {
+	result := &BatchInsertResult{Batches: []InsertBatch{{FirstId: 10, Count: 2}, {FirstId: 20, Count: 1}}}
+	assert.EqualValues(t, []int64{10, 11, 20}, result.Ids())
+}
+
+func TestInitialMaxAllowedPacket(t *testing.T) {
+	db, d := openCountingDB(t)
+	logger := &recordLogger{}
+	mc := &MysqlClient{config: &Config{pool: db, logger: logger, maxAllowedPacket: 1024}}
+	mc.initialMaxAllowedPacket(context.Background())
+	assert.EqualValues(t, 1024, mc.config.maxAllowedPacket)
+	assert.Empty(t, d.queries)
+
+	mc.config.maxAllowedPacket = 0
+	mc.initialMaxAllowedPacket(context.Background())
+	assert.EqualValues(t, []string{"SELECT @@max_allowed_packet"}, d.queries)
+	assert.EqualValues(t, defaultMaxAllowedPacket, mc.config.maxAllowedPacket)
+	assert.EqualValues(t, 1, len(logger.messages))
+}
+
+func TestMysqlClient_BatchInsert(t *testing.T) {
+	once.Do(setup)
+	users := make([]*crudUser, 0)
+	for i := 0; i < 1000; i++ {
+		users = append(users, &crudUser{Name: "test name", Age: 21})
+	}
+	result, err := mysqlClient.BatchInsert("userinfo", users)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1000, result.RowsAffected)
+	assert.EqualValues(t, result.Ids()[999], users[999].Id)
+}
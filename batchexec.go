@@ -0,0 +1,115 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+)
+
+type BatchCallback func(tx *sql.Tx) (int, error)
+
+type BatchExecResult struct {
+	// RowsAffected holds one entry per executed item
+	RowsAffected []int64
+	Total        int64
+	// Committed is the number of items whose changes were committed
+	Committed int
+}
+
+type batchExecConfig struct {
+	commitEvery int
+}
+
+type BatchExecOption func(*batchExecConfig)
+
+// CommitEvery commits after every n items instead of once at the end. When an item fails,
+// the chunks before it stay committed and only the failing chunk is rolled back.
+func CommitEvery(n int) BatchExecOption {
+	return func(c *batchExecConfig) {
+		c.commitEvery = n
+	}
+}
+
+// BatchUpdate runs callback in one transaction and returns the count it reports; any error rolls everything back.
+func (mc *MysqlClient) BatchUpdate(callback BatchCallback) (int, error) {
+	return mc.BatchUpdateContext(context.Background(), callback)
+}
+
+func (mc *MysqlClient) BatchUpdateContext(ctx context.Context, callback BatchCallback) (int, error) {
+	var count int
+	err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		count, err = callback(tx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// BatchExec prepares query once and executes it with each entry of argsList inside a transaction.
+// The first failing item rolls back its transaction and is reported as a *BatchError.
+func (mc *MysqlClient) BatchExec(query string, argsList [][]interface{}, opts ...BatchExecOption) (*BatchExecResult, error) {
+	return mc.BatchExecContext(context.Background(), query, argsList, opts...)
+}
+
+func (mc *MysqlClient) BatchExecContext(ctx context.Context, query string, argsList [][]interface{}, opts ...BatchExecOption) (*BatchExecResult, error) {
+	config := &batchExecConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	chunk := config.commitEvery
+	if chunk <= 0 || chunk > len(argsList) {
+		chunk = len(argsList)
+	}
+	result := &BatchExecResult{RowsAffected: make([]int64, 0, len(argsList))}
+	for start := 0; start < len(argsList); start += chunk {
+		end := start + chunk
+		if end > len(argsList) {
+			end = len(argsList)
+		}
+		var rowsAffected []int64
+		err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+			var err error
+			rowsAffected, err = mc.batchExecTx(ctx, tx, query, argsList[start:end], start)
+			return err
+		})
+		if err != nil {
+			if config.commitEvery > 0 {
+				return result, err
+			}
+			return nil, err
+		}
+		for _, n := range rowsAffected {
+			result.RowsAffected = append(result.RowsAffected, n)
+			result.Total += n
+		}
+		result.Committed = end
+	}
+	return result, nil
+}
+
+func (mc *MysqlClient) batchExecTx(ctx context.Context, tx *sql.Tx, query string, argsList [][]interface{}, offset int) ([]int64, error) {
+	stm, err := mc.TxStmtContext(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stm.Close()
+	rowsAffected := make([]int64, 0, len(argsList))
+	for i, args := range argsList {
+		args, err := mc.getConverters().encodeArgs(args)
+		if err != nil {
+			return nil, &BatchError{Index: offset + i, Err: err}
+		}
+		result, err := stm.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, &BatchError{Index: offset + i, Err: err}
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, &BatchError{Index: offset + i, Err: err}
+		}
+		rowsAffected = append(rowsAffected, n)
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,44 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestBatchError(t *testing.T) {
+	err := error(&BatchError{Index: 3, Err: sql.ErrTxDone})
+	assert.EqualValues(t, "batch item 3: sql: transaction has already been committed or rolled back", err.Error())
+	assert.True(t, errors.Is(err, sql.ErrTxDone))
+}
+
+func TestMysqlClient_BatchExec(t *testing.T) {
+	once.Do(setup)
+	var argsList [][]interface{}
+	for i := 3; i <= 1002; i++ {
+		argsList = append(argsList, []interface{}{"test update name -" + strconv.Itoa(i), i})
+	}
+	result, err := mysqlClient.BatchExec("update userinfo set name = ? where id = ?", argsList, CommitEvery(100))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1000, len(result.RowsAffected))
+	assert.EqualValues(t, 1000, result.Committed)
+}
+
+func TestMysqlClient_BatchUpdate(t *testing.T) {
+	once.Do(setup)
+	count, err := mysqlClient.BatchUpdate(func(tx *sql.Tx) (int, error) {
+		totalCount := 0
+		for i := 3; i <= 12; i++ {
+			_, err := tx.Exec("update userinfo set age = ? where id = ?", 22, i)
+			if err != nil {
+				return 0, err
+			}
+			totalCount++
+		}
+		return totalCount, nil
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 10, count)
+}
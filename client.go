@@ -1,119 +1,112 @@
 package mysqlclient
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/sillyhatxu/mysql-client/customerrors"
+	"log"
 	"sync"
 )
 
 type MysqlClient struct {
-	config *Config
-	mu     sync.Mutex
+	config     *Config
+	mu         sync.Mutex
+	converters *converterRegistry
+	stmts      *stmtCache
+	// unscoped disables the soft-delete filter and makes deletes physical
+	unscoped bool
 }
 
 func NewMysqlClient(opts ...Option) (*MysqlClient, error) {
+	return NewMysqlClientContext(context.Background(), opts...)
+}
+
+// NewMysqlClientContext is NewMysqlClient with the startup ping and flyway migrations bounded by ctx.
+func NewMysqlClientContext(ctx context.Context, opts ...Option) (*MysqlClient, error) {
 	//default
 	config := &Config{
-		ddlPath: "",
-		flyway:  false,
+		ddlPath:          "",
+		flyway:           false,
+		mappingMode:      MappingIgnore,
+		logger:           log.Default(),
+		maxAllowedPacket: 0,
+		batchSize:        1000,
+		stmtCacheSize:    100,
+		inListSize:       1000,
 	}
 	for _, opt := range opts {
 		opt(config)
 	}
 	mc := &MysqlClient{
-		config: config,
+		config:     config,
+		converters: newConverterRegistry(),
 	}
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
-	err := mc.validate()
+	err := mc.validate(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = mc.initialFlayway()
+	mc.initialStmtCache(ctx)
+	mc.initialMaxAllowedPacket(ctx)
+	err = mc.initialFlayway(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return mc, nil
 }
 
-func (mc *MysqlClient) validate() error {
+func (mc *MysqlClient) validate(ctx context.Context) error {
 	if mc.config == nil {
 		return customerrors.CheckConfigNilError
 	}
 	if mc.config.pool == nil {
 		return customerrors.CheckDBPoolError
 	}
-	return mc.Ping()
+	return mc.PingContext(ctx)
+}
+
+// WithMappingMode returns a client sharing this client's pool and converters whose struct mapping uses mappingMode.
+func (mc *MysqlClient) WithMappingMode(mappingMode MappingMode) *MysqlClient {
+	config := *mc.config
+	config.mappingMode = mappingMode
+	return &MysqlClient{config: &config, converters: mc.converters, stmts: mc.stmts, unscoped: mc.unscoped}
+}
+
+// Unscoped returns a client sharing this client's pool and converters that sees soft-deleted rows
+// and deletes rows physically.
+func (mc *MysqlClient) Unscoped() *MysqlClient {
+	return &MysqlClient{config: mc.config, converters: mc.converters, stmts: mc.stmts, unscoped: true}
 }
 
 func (mc *MysqlClient) Ping() error {
-	return mc.GetDB().Ping()
+	return mc.PingContext(context.Background())
+}
+
+func (mc *MysqlClient) PingContext(ctx context.Context) error {
+	return mc.GetDB().PingContext(ctx)
 }
 
 func (mc *MysqlClient) GetDB() *sql.DB {
 	return mc.config.pool
 }
 
-func (mc *MysqlClient) GetTransaction() (*sql.Tx, error) {
-	return mc.GetDB().Begin()
+// GetTransaction begins a transaction the caller commits or rolls back. opts may set the isolation level
+// and read-only mode; timeouts are only supported by Transaction, which knows when the transaction ends.
+func (mc *MysqlClient) GetTransaction(opts ...TxOption) (*sql.Tx, error) {
+	return mc.GetTransactionContext(context.Background(), opts...)
+}
+
+func (mc *MysqlClient) GetTransactionContext(ctx context.Context, opts ...TxOption) (*sql.Tx, error) {
+	config := newTxConfig(opts)
+	if config.timeout > 0 || config.sessionSQL() != "" {
+		return nil, fmt.Errorf("transaction timeouts are only supported by Transaction")
+	}
+	return mc.GetDB().BeginTx(ctx, &config.options)
 }
 
-//func (mc *MysqlClient) FindMapArray(sql string, args ...interface{}) ([]map[string]interface{}, error) {
-//	tx, err := mc.GetTransaction()
-//	if err != nil {
-//		logrus.Errorf("get transaction error; %v", err)
-//		return nil, err
-//	}
-//	defer tx.Commit()
-//	rows, err := tx.Query(sql, args...)
-//	if err != nil {
-//		logrus.Errorf("query error; %v", err)
-//		return nil, err
-//	}
-//	defer rows.Close()
-//	columns, err := rows.Columns()
-//	if err != nil {
-//		logrus.Errorf("rows.Columns() error; %v", err)
-//		return nil, err
-//	}
-//	//values是每个列的值，这里获取到byte里
-//	values := make([][]byte, len(columns))
-//	//query.Scan的参数，因为每次查询出来的列是不定长的，用len(cols)定住当次查询的长度
-//	scans := make([]interface{}, len(columns))
-//	//让每一行数据都填充到[][]byte里面
-//	for i := range values {
-//		scans[i] = &values[i]
-//	}
-//	//最后得到的map
-//	var results []map[string]interface{}
-//	for rows.Next() { //循环，让游标往下推
-//		if err := rows.Scan(scans...); err != nil { //query.Scan查询出来的不定长值放到scans[i] = &values[i],也就是每行都放在values里
-//			return nil, err
-//		}
-//		row := make(map[string]interface{}) //每行数据
-//		for k, v := range values {          //每行数据是放在values里面，现在把它挪到row里
-//			key := columns[k]
-//			//valueType := reflect.TypeOf(v)
-//			//log.Info(valueType)
-//			row[key] = string(v)
-//		}
-//		results = append(results, row)
-//	}
-//	return results, nil
-//}
-//
-//func (mc *MysqlClient) FindMapFirst(sql string, args ...interface{}) (map[string]interface{}, error) {
-//	array, err := mc.FindMapArray(sql, args...)
-//	if err != nil {
-//		return nil, err
-//	}
-//	if array == nil || len(array) == 0 {
-//		return nil, nil
-//	}
-//	return array[0], nil
-//}
-//
 //func (mc *MysqlClient) FindList(sql string, input interface{}, args ...interface{}) error {
 //	if isSlice(input) {
 //		return fmt.Errorf("%v must be a slice", input)
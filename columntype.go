@@ -0,0 +1,153 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type columnInfo struct {
+	name           string
+	typeName       string
+	precision      int64
+	scale          int64
+	hasDecimalSize bool
+}
+
+func newColumnInfo(columnType *sql.ColumnType) columnInfo {
+	precision, scale, ok := columnType.DecimalSize()
+	return columnInfo{
+		name:           columnType.Name(),
+		typeName:       columnType.DatabaseTypeName(),
+		precision:      precision,
+		scale:          scale,
+		hasDecimalSize: ok,
+	}
+}
+
+// columnValue converts a raw driver value into a Go value chosen by the column's database type:
+// integers become int64 (uint64 when an unsigned BIGINT overflows int64), FLOAT/DOUBLE float64,
+// DECIMAL an int64 when it has no scale and fits, otherwise its exact string,
+// BIT uint64, DATE/DATETIME/TIMESTAMP time.Time, binary columns []byte and everything else string.
+// TINYINT(1) and BIT(1) can not be told apart from wider TINYINT and BIT columns on the wire and are
+// returned as int64 and uint64; decode into a struct with a bool field to get booleans.
+func columnValue(column columnInfo, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	typeName := column.typeName
+	unsigned := strings.HasPrefix(typeName, "UNSIGNED ")
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+	switch typeName {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		return integerValue(value, unsigned)
+	case "FLOAT", "DOUBLE":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case float32:
+			return float64(v), nil
+		}
+		return strconv.ParseFloat(stringValue(value), 64)
+	case "DECIMAL":
+		if column.hasDecimalSize && column.scale == 0 && column.precision <= 18 {
+			return strconv.ParseInt(stringValue(value), 10, 64)
+		}
+		return stringValue(value), nil
+	case "BIT":
+		b, ok := value.([]byte)
+		if !ok {
+			return value, nil
+		}
+		buf := make([]byte, 8)
+		copy(buf[8-len(b):], b)
+		return binary.BigEndian.Uint64(buf), nil
+	case "DATE", "DATETIME", "TIMESTAMP":
+		if t, ok := value.(time.Time); ok {
+			return t, nil
+		}
+		s := stringValue(value)
+		if strings.HasPrefix(s, "0000-00-00") {
+			return time.Time{}, nil
+		}
+		return parseTime(s)
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "GEOMETRY":
+		if b, ok := value.([]byte); ok {
+			return append([]byte(nil), b...), nil
+		}
+		return value, nil
+	}
+	return stringValue(value), nil
+}
+
+func integerValue(value interface{}, unsigned bool) (interface{}, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		if v > 1<<63-1 {
+			return v, nil
+		}
+		return int64(v), nil
+	}
+	s := stringValue(value)
+	if unsigned {
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if u > 1<<63-1 {
+			return u, nil
+		}
+		return int64(u), nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func scanMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]columnInfo, len(columnTypes))
+	cols := make([]interface{}, len(columnTypes))
+	colPtrs := make([]interface{}, len(columnTypes))
+	for i, columnType := range columnTypes {
+		columns[i] = newColumnInfo(columnType)
+		colPtrs[i] = &cols[i]
+	}
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		err = rows.Scan(colPtrs...)
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			value, err := columnValue(column, cols[i])
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %v", column.name, err)
+			}
+			row[column.name] = value
+		}
+		results = append(results, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
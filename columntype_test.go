@@ -0,0 +1,49 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestColumnValue(t *testing.T) {
+	value, err := columnValue(columnInfo{typeName: "INT"}, []byte("21"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, int64(21), value)
+
+	value, err = columnValue(columnInfo{typeName: "UNSIGNED BIGINT"}, []byte("18446744073709551615"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, uint64(18446744073709551615), value)
+
+	value, err = columnValue(columnInfo{typeName: "DOUBLE"}, []byte("1.5"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1.5, value)
+
+	value, err = columnValue(columnInfo{typeName: "DECIMAL", precision: 10, scale: 2, hasDecimalSize: true}, []byte("12.30"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "12.30", value)
+
+	value, err = columnValue(columnInfo{typeName: "DECIMAL", precision: 10, scale: 0, hasDecimalSize: true}, []byte("1230"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, int64(1230), value)
+
+	value, err = columnValue(columnInfo{typeName: "BIT"}, []byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), value)
+
+	value, err = columnValue(columnInfo{typeName: "BIT"}, []byte{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(258), value)
+
+	value, err = columnValue(columnInfo{typeName: "DATETIME"}, []byte("2019-02-27 05:39:55"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2019, value.(time.Time).Year())
+
+	value, err = columnValue(columnInfo{typeName: "VARCHAR"}, []byte("test name"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "test name", value)
+
+	value, err = columnValue(columnInfo{typeName: "VARCHAR"}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
@@ -0,0 +1,179 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02",
+}
+
+// convertAssign stores a driver value (nil, int64, float64, bool, []byte, string or time.Time) into dest.
+func convertAssign(dest reflect.Value, src interface{}) error {
+	if dest.CanAddr() && dest.Addr().Type().Implements(scannerType) {
+		return dest.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+	return convertValue(dest, src)
+}
+
+// convertValue is convertAssign for destinations already known not to implement sql.Scanner.
+func convertValue(dest reflect.Value, src interface{}) error {
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	if dest.Kind() == reflect.Ptr {
+		value := reflect.New(dest.Type().Elem())
+		err := convertAssign(value.Elem(), src)
+		if err != nil {
+			return err
+		}
+		dest.Set(value)
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		if dest.Kind() == reflect.Slice && dest.Type().Elem().Kind() == reflect.Uint8 {
+			dest.SetBytes(append([]byte(nil), s...))
+			return nil
+		}
+		return convertString(dest, string(s))
+	case string:
+		return convertString(dest, s)
+	case time.Time:
+		switch {
+		case dest.Type() == timeType:
+			dest.Set(reflect.ValueOf(s))
+			return nil
+		case dest.Kind() == reflect.String:
+			dest.SetString(s.Format(time.RFC3339Nano))
+			return nil
+		case dest.Kind() == reflect.Int64:
+			dest.SetInt(s.UnixNano() / int64(time.Millisecond))
+			return nil
+		}
+	case int64:
+		switch dest.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if dest.OverflowInt(s) {
+				return fmt.Errorf("value %d overflows %v", s, dest.Type())
+			}
+			dest.SetInt(s)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if s < 0 || dest.OverflowUint(uint64(s)) {
+				return fmt.Errorf("value %d overflows %v", s, dest.Type())
+			}
+			dest.SetUint(uint64(s))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			dest.SetFloat(float64(s))
+			return nil
+		case reflect.Bool:
+			dest.SetBool(s != 0)
+			return nil
+		case reflect.String:
+			dest.SetString(strconv.FormatInt(s, 10))
+			return nil
+		}
+	case float64:
+		return convertString(dest, strconv.FormatFloat(s, 'g', -1, 64))
+	case float32:
+		return convertString(dest, strconv.FormatFloat(float64(s), 'g', -1, 32))
+	case bool:
+		switch dest.Kind() {
+		case reflect.Bool:
+			dest.SetBool(s)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if s {
+				dest.SetInt(1)
+			} else {
+				dest.SetInt(0)
+			}
+			return nil
+		case reflect.String:
+			dest.SetString(strconv.FormatBool(s))
+			return nil
+		}
+	}
+	srcV := reflect.ValueOf(src)
+	if srcV.Type().ConvertibleTo(dest.Type()) {
+		dest.Set(srcV.Convert(dest.Type()))
+		return nil
+	}
+	return fmt.Errorf("unsupported conversion from %T to %v", src, dest.Type())
+}
+
+func convertString(dest reflect.Value, s string) error {
+	if dest.Type() == timeType {
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		dest.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch dest.Kind() {
+	case reflect.String:
+		dest.SetString(s)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, dest.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dest.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, dest.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dest.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dest.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dest.SetFloat(f)
+		return nil
+	case reflect.Bool:
+		// BIT(1) columns come back as a single raw byte
+		if len(s) == 1 && (s[0] == 0 || s[0] == 1) {
+			dest.SetBool(s[0] == 1)
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		dest.SetBool(b)
+		return nil
+	case reflect.Slice:
+		if dest.Type().Elem().Kind() == reflect.Uint8 {
+			dest.SetBytes([]byte(s))
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported conversion from %q to %v", s, dest.Type())
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
+}
@@ -0,0 +1,49 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConvertAssign(t *testing.T) {
+	var i int
+	assert.Nil(t, convertAssign(reflect.ValueOf(&i).Elem(), int64(21)))
+	assert.EqualValues(t, 21, i)
+	assert.Nil(t, convertAssign(reflect.ValueOf(&i).Elem(), []byte("42")))
+	assert.EqualValues(t, 42, i)
+
+	var s string
+	assert.Nil(t, convertAssign(reflect.ValueOf(&s).Elem(), []byte("test name")))
+	assert.EqualValues(t, "test name", s)
+
+	var b bool
+	assert.Nil(t, convertAssign(reflect.ValueOf(&b).Elem(), []byte{1}))
+	assert.EqualValues(t, true, b)
+	assert.Nil(t, convertAssign(reflect.ValueOf(&b).Elem(), int64(0)))
+	assert.EqualValues(t, false, b)
+
+	var u uint8
+	assert.NotNil(t, convertAssign(reflect.ValueOf(&u).Elem(), int64(-1)))
+
+	now := time.Now()
+	var tm time.Time
+	assert.Nil(t, convertAssign(reflect.ValueOf(&tm).Elem(), now))
+	assert.EqualValues(t, now, tm)
+	assert.Nil(t, convertAssign(reflect.ValueOf(&tm).Elem(), []byte("1989-06-09")))
+	assert.EqualValues(t, 1989, tm.Year())
+}
+
+func TestConvertAssignPointerAndScanner(t *testing.T) {
+	var p *string
+	assert.Nil(t, convertAssign(reflect.ValueOf(&p).Elem(), []byte("description")))
+	assert.EqualValues(t, "description", *p)
+	assert.Nil(t, convertAssign(reflect.ValueOf(&p).Elem(), nil))
+	assert.Nil(t, p)
+
+	var ns sql.NullString
+	assert.Nil(t, convertAssign(reflect.ValueOf(&ns).Elem(), []byte("name")))
+	assert.EqualValues(t, sql.NullString{String: "name", Valid: true}, ns)
+}
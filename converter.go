@@ -0,0 +1,257 @@
+package mysqlclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DecodeFunc stores a driver value (never nil) into dest.
+type DecodeFunc func(dest reflect.Value, src interface{}) error
+
+// EncodeFunc turns a Go value into an argument the driver accepts.
+type EncodeFunc func(value interface{}) (interface{}, error)
+
+// Set is a MySQL SET value, bound and read as a comma separated string.
+type Set []string
+
+type typeConverter struct {
+	decode DecodeFunc
+	encode EncodeFunc
+}
+
+type converterRegistry struct {
+	mu      sync.RWMutex
+	types   map[reflect.Type]typeConverter
+	columns map[string]DecodeFunc
+}
+
+var (
+	ratType = reflect.TypeOf(big.Rat{})
+
+	setType = reflect.TypeOf(Set{})
+
+	defaultConverters = newConverterRegistry()
+)
+
+func newConverterRegistry() *converterRegistry {
+	r := &converterRegistry{
+		types:   make(map[reflect.Type]typeConverter),
+		columns: make(map[string]DecodeFunc),
+	}
+	r.types[ratType] = typeConverter{decode: decodeRat}
+	r.types[reflect.PointerTo(ratType)] = typeConverter{encode: encodeRat}
+	r.types[setType] = typeConverter{decode: decodeSet, encode: encodeSet}
+	r.columns["BIT"] = decodeBit
+	r.columns["JSON"] = decodeJSON
+	r.columns["SET"] = decodeSet
+	return r
+}
+
+// RegisterTypeConverter registers how values of Go type t are decoded from columns and encoded as arguments.
+// Either function may be nil. A type converter takes precedence over a column converter.
+func (mc *MysqlClient) RegisterTypeConverter(t reflect.Type, decode DecodeFunc, encode EncodeFunc) {
+	r := mc.getConverters()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[t] = typeConverter{decode: decode, encode: encode}
+}
+
+// RegisterColumnConverter registers how values of a column type, as reported by DatabaseTypeName
+// (e.g. "JSON", "BIT", "SET"), are decoded when the destination field has no type converter.
+func (mc *MysqlClient) RegisterColumnConverter(databaseTypeName string, decode DecodeFunc) {
+	r := mc.getConverters()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.columns[strings.ToUpper(databaseTypeName)] = decode
+}
+
+func (mc *MysqlClient) getConverters() *converterRegistry {
+	if mc.converters == nil {
+		return defaultConverters
+	}
+	return mc.converters
+}
+
+func (r *converterRegistry) typeConverter(t reflect.Type) (typeConverter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.types[t]
+	return c, ok
+}
+
+func (r *converterRegistry) columnConverter(databaseTypeName string) (DecodeFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	decode, ok := r.columns[strings.TrimPrefix(databaseTypeName, "UNSIGNED ")]
+	return decode, ok
+}
+
+// decode stores src into dest, trying in order a type converter, sql.Scanner, a column converter
+// and finally the default conversion.
+func (r *converterRegistry) decode(dest reflect.Value, column columnInfo, src interface{}) error {
+	return r.decoder(dest.Type(), column)(dest, src)
+}
+
+// decoder resolves once how values of column are stored into a destination of type t,
+// so that a result set pays for the converter lookups per column instead of per row.
+func (r *converterRegistry) decoder(t reflect.Type, column columnInfo) DecodeFunc {
+	if c, ok := r.typeConverter(t); ok && c.decode != nil {
+		return nullable(c.decode)
+	}
+	if t.Kind() == reflect.Ptr {
+		elemT := t.Elem()
+		decode := r.decoder(elemT, column)
+		return func(dest reflect.Value, src interface{}) error {
+			if src == nil {
+				dest.Set(reflect.Zero(t))
+				return nil
+			}
+			value := reflect.New(elemT)
+			err := decode(value.Elem(), src)
+			if err != nil {
+				return err
+			}
+			dest.Set(value)
+			return nil
+		}
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return convertAssign
+	}
+	if decode, ok := r.columnConverter(column.typeName); ok {
+		return nullable(decode)
+	}
+	return convertValue
+}
+
+// nullable lets decode, which never sees NULL, handle it by storing the zero value.
+func nullable(decode DecodeFunc) DecodeFunc {
+	return func(dest reflect.Value, src interface{}) error {
+		if src == nil {
+			return convertAssign(dest, nil)
+		}
+		return decode(dest, src)
+	}
+}
+
+func (r *converterRegistry) encodeArgs(args []interface{}) ([]interface{}, error) {
+	var encoded []interface{}
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		c, ok := r.typeConverter(reflect.TypeOf(arg))
+		if !ok || c.encode == nil {
+			continue
+		}
+		value, err := c.encode(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %v", i, err)
+		}
+		if encoded == nil {
+			encoded = append([]interface{}(nil), args...)
+		}
+		encoded[i] = value
+	}
+	if encoded == nil {
+		return args, nil
+	}
+	return encoded, nil
+}
+
+func decodeBit(dest reflect.Value, src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok || dest.Kind() == reflect.String || dest.Kind() == reflect.Slice {
+		return convertAssign(dest, src)
+	}
+	var u uint64
+	for _, x := range b {
+		u = u<<8 | uint64(x)
+	}
+	switch dest.Kind() {
+	case reflect.Bool:
+		dest.SetBool(u != 0)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if dest.OverflowUint(u) {
+			return fmt.Errorf("value %d overflows %v", u, dest.Type())
+		}
+		dest.SetUint(u)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if u > 1<<63-1 || dest.OverflowInt(int64(u)) {
+			return fmt.Errorf("value %d overflows %v", u, dest.Type())
+		}
+		dest.SetInt(int64(u))
+		return nil
+	}
+	return convertAssign(dest, src)
+}
+
+func decodeJSON(dest reflect.Value, src interface{}) error {
+	switch dest.Kind() {
+	case reflect.String:
+		return convertAssign(dest, src)
+	case reflect.Slice:
+		if dest.Type().Elem().Kind() == reflect.Uint8 {
+			return convertAssign(dest, src)
+		}
+	}
+	return json.Unmarshal([]byte(stringValue(src)), dest.Addr().Interface())
+}
+
+func decodeSet(dest reflect.Value, src interface{}) error {
+	if dest.Kind() != reflect.Slice || dest.Type().Elem().Kind() != reflect.String {
+		return convertAssign(dest, src)
+	}
+	s := stringValue(src)
+	values := reflect.MakeSlice(dest.Type(), 0, 0)
+	if s != "" {
+		for _, value := range strings.Split(s, ",") {
+			values = reflect.Append(values, reflect.ValueOf(value).Convert(dest.Type().Elem()))
+		}
+	}
+	dest.Set(values)
+	return nil
+}
+
+func encodeSet(value interface{}) (interface{}, error) {
+	return strings.Join(value.(Set), ","), nil
+}
+
+func decodeRat(dest reflect.Value, src interface{}) error {
+	s := stringValue(src)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("cannot parse %q as decimal", s)
+	}
+	dest.Set(reflect.ValueOf(r).Elem())
+	return nil
+}
+
+func encodeRat(value interface{}) (interface{}, error) {
+	r := value.(*big.Rat)
+	if r == nil {
+		return nil, nil
+	}
+	return ratString(r), nil
+}
+
+// ratString formats r as an exact decimal when it has at most 30 fractional digits, the DECIMAL scale limit.
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	for scale := 1; scale < 30; scale++ {
+		s := r.FloatString(scale)
+		parsed, ok := new(big.Rat).SetString(s)
+		if ok && parsed.Cmp(r) == 0 {
+			return s
+		}
+	}
+	return r.FloatString(30)
+}
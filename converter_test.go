@@ -0,0 +1,65 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConverterRegistryDecode(t *testing.T) {
+	r := newConverterRegistry()
+
+	var deleted bool
+	assert.Nil(t, r.decode(reflect.ValueOf(&deleted).Elem(), columnInfo{typeName: "BIT"}, []byte{1}))
+	assert.True(t, deleted)
+
+	var flags uint16
+	assert.Nil(t, r.decode(reflect.ValueOf(&flags).Elem(), columnInfo{typeName: "BIT"}, []byte{1, 2}))
+	assert.EqualValues(t, 258, flags)
+
+	var payload map[string]interface{}
+	assert.Nil(t, r.decode(reflect.ValueOf(&payload).Elem(), columnInfo{typeName: "JSON"}, []byte(`{"name":"test"}`)))
+	assert.EqualValues(t, "test", payload["name"])
+
+	var raw string
+	assert.Nil(t, r.decode(reflect.ValueOf(&raw).Elem(), columnInfo{typeName: "JSON"}, []byte(`{"name":"test"}`)))
+	assert.EqualValues(t, `{"name":"test"}`, raw)
+
+	var amount *big.Rat
+	assert.Nil(t, r.decode(reflect.ValueOf(&amount).Elem(), columnInfo{typeName: "DECIMAL"}, []byte("12.30")))
+	assert.EqualValues(t, "123/10", amount.RatString())
+
+	var set Set
+	assert.Nil(t, r.decode(reflect.ValueOf(&set).Elem(), columnInfo{typeName: "VARCHAR"}, []byte("a,b")))
+	assert.EqualValues(t, Set{"a", "b"}, set)
+
+	var tags []string
+	assert.Nil(t, r.decode(reflect.ValueOf(&tags).Elem(), columnInfo{typeName: "SET"}, []byte("")))
+	assert.EqualValues(t, []string{}, tags)
+}
+
+func TestConverterRegistryCustom(t *testing.T) {
+	mc := &MysqlClient{converters: newConverterRegistry()}
+	type upper string
+	mc.RegisterTypeConverter(reflect.TypeOf(upper("")), func(dest reflect.Value, src interface{}) error {
+		dest.SetString(strings.ToUpper(stringValue(src)))
+		return nil
+	}, func(value interface{}) (interface{}, error) {
+		return strings.ToLower(string(value.(upper))), nil
+	})
+	var name upper
+	assert.Nil(t, mc.converters.decode(reflect.ValueOf(&name).Elem(), columnInfo{typeName: "VARCHAR"}, []byte("test")))
+	assert.EqualValues(t, "TEST", name)
+
+	args, err := mc.converters.encodeArgs([]interface{}{upper("TEST"), 1, nil, Set{"a", "b"}, big.NewRat(1, 4)})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []interface{}{"test", 1, nil, "a,b", "0.25"}, args)
+}
+
+func TestRatString(t *testing.T) {
+	assert.EqualValues(t, "12", ratString(big.NewRat(12, 1)))
+	assert.EqualValues(t, "0.125", ratString(big.NewRat(1, 8)))
+	assert.EqualValues(t, "0.333333333333333333333333333333", ratString(big.NewRat(1, 3)))
+}
@@ -0,0 +1,359 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// execer is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// InsertStruct inserts the mapped fields of input, a struct pointer, into table.
+// Zero-valued autoincr and omitempty fields are left to their column defaults,
+// and the generated id is written back into the autoincr primary key field.
+// A struct whose only pk field is an integer and which has no autoincr field treats that pk as autoincr.
+func (mc *MysqlClient) InsertStruct(table string, input interface{}) (int64, error) {
+	return mc.insertStruct(context.Background(), mc.GetDB(), table, input)
+}
+
+func (mc *MysqlClient) InsertStructContext(ctx context.Context, table string, input interface{}) (int64, error) {
+	return mc.insertStruct(ctx, mc.session(ctx), table, input)
+}
+
+func (mc *MysqlClient) InsertStructTx(tx *sql.Tx, table string, input interface{}) (int64, error) {
+	return mc.insertStruct(context.Background(), tx, table, input)
+}
+
+func (mc *MysqlClient) InsertStructTxContext(ctx context.Context, tx *sql.Tx, table string, input interface{}) (int64, error) {
+	return mc.insertStruct(ctx, tx, table, input)
+}
+
+func (mc *MysqlClient) insertStruct(ctx context.Context, e execer, table string, input interface{}) (int64, error) {
+	structV, err := structValue(input)
+	if err != nil {
+		return 0, err
+	}
+	columns, args, err := insertValues(structV)
+	if err != nil {
+		return 0, err
+	}
+	args, err = mc.getConverters().encodeArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	result, err := e.ExecContext(ctx, insertSQL(table, columns, 1), args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	err = setAutoIncrement(structV, id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// structValue returns the struct a non-nil struct pointer points to.
+func structValue(input interface{}) (reflect.Value, error) {
+	inputT := reflect.TypeOf(input)
+	if inputT == nil || !isStructPtr(inputT) || !isMappableStruct(inputT.Elem()) || reflect.ValueOf(input).IsNil() {
+		return reflect.Value{}, fmt.Errorf("%v must be a non-nil struct pointer", input)
+	}
+	return reflect.ValueOf(input).Elem(), nil
+}
+
+func insertValues(structV reflect.Value) ([]string, []interface{}, error) {
+	var columns []string
+	var args []interface{}
+	for _, info := range cachedStructColumns(structV.Type()) {
+		if info.autoIncr || info.omitEmpty {
+			field, ok := fieldByIndex(structV, info.index, false)
+			if !ok || field.IsZero() {
+				continue
+			}
+		}
+		value, err := fieldValue(structV, info)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, info.column)
+		args = append(args, value)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("%v has no columns to insert", structV.Type())
+	}
+	return columns, args, nil
+}
+
+// fieldValue reads the argument bound for a field; fields under a nil embedded pointer are NULL.
+func fieldValue(structV reflect.Value, info fieldInfo) (interface{}, error) {
+	field, ok := fieldByIndex(structV, info.index, false)
+	if !ok {
+		return nil, nil
+	}
+	if !info.json {
+		return field.Interface(), nil
+	}
+	if (field.Kind() == reflect.Ptr || field.Kind() == reflect.Map || field.Kind() == reflect.Slice) && field.IsNil() {
+		return nil, nil
+	}
+	b, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %v", info.column, err)
+	}
+	return string(b), nil
+}
+
+func setAutoIncrement(structV reflect.Value, id int64) error {
+	for _, info := range cachedStructColumns(structV.Type()) {
+		if !info.autoIncr {
+			continue
+		}
+		field, ok := fieldByIndex(structV, info.index, true)
+		if !ok || !field.IsZero() {
+			return nil
+		}
+		return convertAssign(field, id)
+	}
+	return nil
+}
+
+func quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// insertSQL builds INSERT INTO table (columns) VALUES (?, ...) with one placeholder group per row.
+func insertSQL(table string, columns []string, rows int) string {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(quoteTable(table))
+	sb.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quoteIdentifier(column))
+	}
+	sb.WriteString(") VALUES ")
+	group := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(group)
+	}
+	return sb.String()
+}
+
+type updateConfig struct {
+	columns  []string
+	snapshot interface{}
+}
+
+type UpdateOption func(*updateConfig)
+
+// OnlyColumns restricts UpdateStruct to the given columns.
+func OnlyColumns(columns ...string) UpdateOption {
+	return func(c *updateConfig) {
+		c.columns = columns
+	}
+}
+
+// ChangedFrom restricts UpdateStruct to the columns whose value differs from snapshot,
+// a copy of the struct taken when it was loaded.
+func ChangedFrom(snapshot interface{}) UpdateOption {
+	return func(c *updateConfig) {
+		c.snapshot = snapshot
+	}
+}
+
+// UpdateStruct updates the row of table whose primary key (the pk field, or the id column) matches input,
+// a struct pointer. By default every column but the keys is written. With a version field the update
+// only applies to the loaded version, increments it, and returns *VersionConflictError when no row matches.
+func (mc *MysqlClient) UpdateStruct(table string, input interface{}, opts ...UpdateOption) (int64, error) {
+	return mc.updateStruct(context.Background(), mc.GetDB(), table, input, opts...)
+}
+
+func (mc *MysqlClient) UpdateStructContext(ctx context.Context, table string, input interface{}, opts ...UpdateOption) (int64, error) {
+	return mc.updateStruct(ctx, mc.session(ctx), table, input, opts...)
+}
+
+func (mc *MysqlClient) UpdateStructTx(tx *sql.Tx, table string, input interface{}, opts ...UpdateOption) (int64, error) {
+	return mc.updateStruct(context.Background(), tx, table, input, opts...)
+}
+
+func (mc *MysqlClient) UpdateStructTxContext(ctx context.Context, tx *sql.Tx, table string, input interface{}, opts ...UpdateOption) (int64, error) {
+	return mc.updateStruct(ctx, tx, table, input, opts...)
+}
+
+func (mc *MysqlClient) updateStruct(ctx context.Context, e execer, table string, input interface{}, opts ...UpdateOption) (int64, error) {
+	config := &updateConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	structV, err := structValue(input)
+	if err != nil {
+		return 0, err
+	}
+	keys, version, err := updateKeys(structV.Type())
+	if err != nil {
+		return 0, err
+	}
+	infos, err := updateColumns(structV, config)
+	if err != nil {
+		return 0, err
+	}
+	if len(infos) == 0 {
+		return 0, nil
+	}
+	var args []interface{}
+	var columns []string
+	for _, info := range infos {
+		value, err := fieldValue(structV, info)
+		if err != nil {
+			return 0, err
+		}
+		columns = append(columns, info.column)
+		args = append(args, value)
+	}
+	var where []string
+	for _, key := range keys {
+		value, err := fieldValue(structV, key)
+		if err != nil {
+			return 0, err
+		}
+		where = append(where, key.column)
+		args = append(args, value)
+	}
+	var currentVersion int64
+	if version != nil {
+		field, _ := fieldByIndex(structV, version.index, false)
+		err = convertAssign(reflect.ValueOf(&currentVersion).Elem(), field.Interface())
+		if err != nil {
+			return 0, fmt.Errorf("version column %s: %v", version.column, err)
+		}
+		args = append(args, currentVersion)
+	}
+	args, err = mc.getConverters().encodeArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	result, err := e.ExecContext(ctx, updateSQL(table, columns, where, version), args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if version == nil {
+		return rowsAffected, nil
+	}
+	if rowsAffected == 0 {
+		return 0, &VersionConflictError{Table: table, Version: currentVersion}
+	}
+	field, _ := fieldByIndex(structV, version.index, false)
+	err = convertAssign(field, currentVersion+1)
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+// updateKeys returns the primary key fields, falling back to the id column, and the version field if any.
+func updateKeys(structT reflect.Type) ([]fieldInfo, *fieldInfo, error) {
+	var keys []fieldInfo
+	var version *fieldInfo
+	var id *fieldInfo
+	for _, info := range cachedStructColumns(structT) {
+		info := info
+		switch {
+		case info.pk:
+			keys = append(keys, info)
+		case info.version:
+			version = &info
+		case info.name == "id":
+			id = &info
+		}
+	}
+	if len(keys) == 0 && id != nil {
+		keys = append(keys, *id)
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("%v has no pk field", structT)
+	}
+	return keys, version, nil
+}
+
+func updateColumns(structV reflect.Value, config *updateConfig) ([]fieldInfo, error) {
+	var only map[string]bool
+	if config.columns != nil {
+		only = make(map[string]bool, len(config.columns))
+		fields := cachedStructFields(structV.Type())
+		for _, column := range config.columns {
+			info, ok := fields[strings.ToLower(column)]
+			if !ok {
+				return nil, fmt.Errorf("%v has no column %s", structV.Type(), column)
+			}
+			only[info.name] = true
+		}
+	}
+	var snapshotV reflect.Value
+	if config.snapshot != nil {
+		snapshotV = reflect.ValueOf(config.snapshot)
+		if snapshotV.Kind() == reflect.Ptr {
+			snapshotV = snapshotV.Elem()
+		}
+		if snapshotV.Type() != structV.Type() {
+			return nil, fmt.Errorf("snapshot %v does not match %v", snapshotV.Type(), structV.Type())
+		}
+	}
+	var infos []fieldInfo
+	for _, info := range cachedStructColumns(structV.Type()) {
+		if info.pk || info.autoIncr || info.version || (only == nil && info.name == "id") {
+			continue
+		}
+		if only != nil && !only[info.name] {
+			continue
+		}
+		if snapshotV.IsValid() {
+			current, _ := fieldByIndex(structV, info.index, false)
+			previous, _ := fieldByIndex(snapshotV, info.index, false)
+			if current.IsValid() == previous.IsValid() && (!current.IsValid() || reflect.DeepEqual(current.Interface(), previous.Interface())) {
+				continue
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func updateSQL(table string, columns []string, where []string, version *fieldInfo) string {
+	var sets []string
+	for _, column := range columns {
+		sets = append(sets, quoteIdentifier(column)+" = ?")
+	}
+	var conditions []string
+	for _, column := range where {
+		conditions = append(conditions, quoteIdentifier(column)+" = ?")
+	}
+	if version != nil {
+		quoted := quoteIdentifier(version.column)
+		sets = append(sets, quoted+" = "+quoted+" + 1")
+		conditions = append(conditions, quoted+" = ?")
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteTable(table), strings.Join(sets, ", "), strings.Join(conditions, " AND "))
+}
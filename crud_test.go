@@ -0,0 +1,155 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type crudUser struct {
+	Id          int64      `db:"id,pk,autoincr"`
+	Name        string     `db:"name"`
+	Age         int        `db:"age"`
+	Description string     `db:"description,omitempty"`
+	Tags        []string   `db:"tags,json"`
+	IsDelete    bool       `db:"is_delete"`
+	CreatedTime *time.Time `db:"created_time,omitempty"`
+}
+
+func TestInsertSQL(t *testing.T) {
+	assert.EqualValues(t, "INSERT INTO `userinfo` (`name`, `age`) VALUES (?, ?)", insertSQL("userinfo", []string{"name", "age"}, 1))
+	assert.EqualValues(t, "INSERT INTO `db`.`userinfo` (`name`) VALUES (?), (?)", insertSQL("db.userinfo", []string{"name"}, 2))
+}
+
+func TestInsertValues(t *testing.T) {
+	user := &crudUser{Name: "test name", Age: 21, Tags: []string{"a"}}
+	columns, args, err := insertValues(reflect.ValueOf(user).Elem())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"name", "age", "tags", "is_delete"}, columns)
+	assert.EqualValues(t, []interface{}{"test name", 21, `["a"]`, false}, args)
+
+	user.Id = 5
+	user.Description = "This is description"
+	columns, _, err = insertValues(reflect.ValueOf(user).Elem())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"id", "name", "age", "description", "tags", "is_delete"}, columns)
+}
+
+func TestSetAutoIncrement(t *testing.T) {
+	user := &crudUser{}
+	assert.Nil(t, setAutoIncrement(reflect.ValueOf(user).Elem(), 12))
+	assert.EqualValues(t, 12, user.Id)
+	assert.Nil(t, setAutoIncrement(reflect.ValueOf(user).Elem(), 13))
+	assert.EqualValues(t, 12, user.Id)
+}
+
+func TestStructValue(t *testing.T) {
+	_, err := structValue(crudUser{})
+	assert.NotNil(t, err)
+	var user *crudUser
+	_, err = structValue(user)
+	assert.NotNil(t, err)
+	_, err = structValue(&crudUser{})
+	assert.Nil(t, err)
+}
+
+type versionedUser struct {
+	Id      int64  `db:"id,pk"`
+	Name    string `db:"name"`
+	Age     int    `db:"age"`
+	Version int    `db:"version,version"`
+}
+
+func TestInsertValues_PkOnly(t *testing.T) {
+	user := &versionedUser{Name: "test name", Age: 21}
+	columns, _, err := insertValues(reflect.ValueOf(user).Elem())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"name", "age", "version"}, columns)
+	assert.Nil(t, setAutoIncrement(reflect.ValueOf(user).Elem(), 12))
+	assert.EqualValues(t, 12, user.Id)
+
+	type codeUser struct {
+		Code string `db:"code,pk"`
+		Name string `db:"name"`
+	}
+	columns, _, err = insertValues(reflect.ValueOf(&codeUser{}).Elem())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"code", "name"}, columns)
+
+	type compositeKey struct {
+		UserId int64 `db:"user_id,pk"`
+		RoleId int64 `db:"role_id,pk"`
+	}
+	columns, _, err = insertValues(reflect.ValueOf(&compositeKey{}).Elem())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"user_id", "role_id"}, columns)
+}
+
+func TestUpdateSQL(t *testing.T) {
+	assert.EqualValues(t, "UPDATE `userinfo` SET `name` = ?, `age` = ? WHERE `id` = ?", updateSQL("userinfo", []string{"name", "age"}, []string{"id"}, nil))
+	keys, version, err := updateKeys(reflect.TypeOf(versionedUser{}))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "id", keys[0].column)
+	assert.EqualValues(t, "UPDATE `userinfo` SET `name` = ?, `version` = `version` + 1 WHERE `id` = ? AND `version` = ?", updateSQL("userinfo", []string{"name"}, []string{"id"}, version))
+}
+
+func TestUpdateKeys(t *testing.T) {
+	type untagged struct {
+		Id   int64
+		Name string
+	}
+	keys, version, err := updateKeys(reflect.TypeOf(untagged{}))
+	assert.Nil(t, err)
+	assert.Nil(t, version)
+	assert.EqualValues(t, "id", keys[0].column)
+
+	type noKey struct {
+		Name string
+	}
+	_, _, err = updateKeys(reflect.TypeOf(noKey{}))
+	assert.NotNil(t, err)
+}
+
+func TestUpdateColumns(t *testing.T) {
+	user := versionedUser{Id: 1, Name: "test name", Age: 21, Version: 3}
+	names := func(infos []fieldInfo) []string {
+		var columns []string
+		for _, info := range infos {
+			columns = append(columns, info.column)
+		}
+		return columns
+	}
+	infos, err := updateColumns(reflect.ValueOf(user), &updateConfig{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"name", "age"}, names(infos))
+
+	infos, err = updateColumns(reflect.ValueOf(user), &updateConfig{columns: []string{"AGE"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"age"}, names(infos))
+
+	_, err = updateColumns(reflect.ValueOf(user), &updateConfig{columns: []string{"missing"}})
+	assert.NotNil(t, err)
+
+	snapshot := user
+	user.Name = "new name"
+	infos, err = updateColumns(reflect.ValueOf(user), &updateConfig{snapshot: &snapshot})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"name"}, names(infos))
+
+	_, err = updateColumns(reflect.ValueOf(user), &updateConfig{snapshot: crudUser{}})
+	assert.NotNil(t, err)
+}
+
+func TestVersionConflictError(t *testing.T) {
+	var err error = &VersionConflictError{Table: "userinfo", Version: 3}
+	assert.EqualValues(t, "userinfo: row was modified concurrently, version 3 is stale", err.Error())
+}
+
+func TestMysqlClient_InsertStruct(t *testing.T) {
+	once.Do(setup)
+	user := &crudUser{Name: "test name", Age: 21}
+	id, err := mysqlClient.InsertStruct("userinfo", user)
+	assert.Nil(t, err)
+	assert.EqualValues(t, id, user.Id)
+}
@@ -0,0 +1,142 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Cursor walks a result set one row at a time without buffering it.
+// It must be closed once the caller is done, typically with defer.
+type Cursor struct {
+	rows    *sql.Rows
+	scanner *rowScanner
+	structT reflect.Type
+	// column is the single result column, resolved on the first scalar Scan
+	column *columnInfo
+	mapper *mapper
+	// scoped is set when the query already filters soft-deleted rows or the client is unscoped
+	scoped bool
+}
+
+// FindCursor runs sql as written. It cannot filter soft-deleted rows before the destination is known,
+// so scanning into a struct with a softdelete field fails unless the client is Unscoped; Iterate filters them.
+func (mc *MysqlClient) FindCursor(sql string, args ...interface{}) (*Cursor, error) {
+	return mc.FindCursorContext(context.Background(), sql, args...)
+}
+
+// FindCursorContext is FindCursor with the query bound to ctx; cancelling ctx ends the iteration with ctx's error.
+func (mc *MysqlClient) FindCursorContext(ctx context.Context, sql string, args ...interface{}) (*Cursor, error) {
+	return mc.findCursor(ctx, sql, nil, args...)
+}
+
+// findCursor runs sql, scoped to soft-delete structT when it is not nil.
+func (mc *MysqlClient) findCursor(ctx context.Context, sql string, structT reflect.Type, args ...interface{}) (*Cursor, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	scoped := mc.unscoped
+	if structT != nil {
+		sql, err = mc.scoped(sql, structT)
+		if err != nil {
+			return nil, err
+		}
+		scoped = true
+	}
+	rows, err := mc.session(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{rows: rows, mapper: mc.mapper(), scoped: scoped}, nil
+}
+
+func (c *Cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan decodes the current row into dest, which is a struct pointer, a pointer to a struct pointer,
+// or a pointer to a scalar when the query returns a single column.
+func (c *Cursor) Scan(dest interface{}) error {
+	destV := reflect.ValueOf(dest)
+	if !destV.IsValid() || destV.Kind() != reflect.Ptr || destV.IsNil() {
+		return fmt.Errorf("%v must be a non-nil pointer", dest)
+	}
+	destV = destV.Elem()
+	if isScalarType(destV.Type()) {
+		if c.column == nil {
+			column, err := scalarColumn(c.rows)
+			if err != nil {
+				return err
+			}
+			c.column = &column
+		}
+		return scanScalar(c.rows, *c.column, destV, c.mapper)
+	}
+	structV := destV
+	if destV.Kind() == reflect.Ptr {
+		structV = reflect.New(destV.Type().Elem()).Elem()
+	}
+	if c.scanner == nil || c.structT != structV.Type() {
+		if _, ok := softDeleteField(structV.Type()); ok && !c.scoped {
+			return fmt.Errorf("%v has a softdelete field that FindCursor cannot filter; use Iterate or Unscoped", structV.Type())
+		}
+		scanner, err := newRowScanner(c.rows, structV.Type(), c.mapper)
+		if err != nil {
+			return err
+		}
+		c.scanner = scanner
+		c.structT = structV.Type()
+	}
+	err := c.scanner.scan(c.rows, structV)
+	if err != nil {
+		return err
+	}
+	if destV.Kind() == reflect.Ptr {
+		destV.Set(structV.Addr())
+	}
+	return nil
+}
+
+func (c *Cursor) Err() error {
+	return c.rows.Err()
+}
+
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
+
+// Iterate yields each row decoded into T. Breaking out of the loop closes the underlying rows;
+// after an error is yielded the iteration stops. Soft-deleted rows of a struct T are left out like Find.
+func Iterate[T any](mc *MysqlClient, sql string, args ...interface{}) iter.Seq2[T, error] {
+	return IterateContext[T](context.Background(), mc, sql, args...)
+}
+
+func IterateContext[T any](ctx context.Context, mc *MysqlClient, sql string, args ...interface{}) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor, err := mc.findCursor(ctx, sql, reflect.TypeOf((*T)(nil)).Elem(), args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer cursor.Close()
+		for cursor.Next() {
+			var item T
+			err = cursor.Scan(&item)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		err = cursor.Err()
+		if err != nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package mysqlclient
+
+import (
+	"github.com/sillyhatxu/mysql-client/example/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMysqlClient_FindCursor(t *testing.T) {
+	once.Do(setup)
+	cursor, err := mysqlClient.FindCursor("select * from user")
+	assert.Nil(t, err)
+	defer cursor.Close()
+	count := 0
+	for cursor.Next() {
+		var user model.User
+		err = cursor.Scan(&user)
+		assert.Nil(t, err)
+		count++
+	}
+	assert.Nil(t, cursor.Err())
+	assert.EqualValues(t, 1, count)
+}
+
+func TestMysqlClient_Iterate(t *testing.T) {
+	once.Do(setup)
+	count := 0
+	for user, err := range Iterate[*model.User](mysqlClient, "select * from user") {
+		assert.Nil(t, err)
+		assert.NotNil(t, user)
+		count++
+	}
+	assert.EqualValues(t, 1, count)
+}
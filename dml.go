@@ -1,81 +1,143 @@
 package mysqlclient
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
 )
 
 func (mc *MysqlClient) Insert(sql string, args ...interface{}) (int64, error) {
-	stm, err := mc.GetDB().Prepare(sql)
-	if err != nil {
-		return 0, err
-	}
-	defer stm.Close()
-	result, err := stm.Exec(args...)
+	return mc.InsertContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) InsertContext(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	result, err := mc.ExecContext(ctx, sql, args...)
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+	return result.LastInsertId, nil
 }
 
 func (mc *MysqlClient) Update(sql string, args ...interface{}) (int64, error) {
-	stm, err := mc.GetDB().Prepare(sql)
-	if err != nil {
-		return 0, err
-	}
-	defer stm.Close()
-	result, err := stm.Exec(args...)
+	return mc.UpdateContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) UpdateContext(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	result, err := mc.ExecContext(ctx, sql, args...)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return result.RowsAffected, nil
 }
 
 func (mc *MysqlClient) Delete(sql string, args ...interface{}) (int64, error) {
-	stm, err := mc.GetDB().Prepare(sql)
-	if err != nil {
-		return 0, err
-	}
-	defer stm.Close()
-	result, err := stm.Exec(args...)
+	return mc.DeleteContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) DeleteContext(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	result, err := mc.ExecContext(ctx, sql, args...)
 	if err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return result.RowsAffected, nil
 }
 
 type TransactionCallback func(*sql.Tx) error
 
-func (mc *MysqlClient) Transaction(callback TransactionCallback) error {
-	tx, err := mc.GetTransaction()
+// Transaction runs callback in a transaction, committing when it returns nil and rolling back otherwise.
+// A panicking callback is rolled back and the panic re-raised; a failed rollback is joined to the callback's error.
+// The callback must not commit or roll back tx itself, which is reported as TxDoneError, joined to its error if it returned one.
+// opts set the isolation level, read-only mode and timeouts, e.g. Transaction(f, Isolation(sql.LevelSerializable)).
+func (mc *MysqlClient) Transaction(callback TransactionCallback, opts ...TxOption) error {
+	return mc.TransactionContext(context.Background(), callback, opts...)
+}
+
+// TransactionContext begins the transaction with ctx; once ctx is done the transaction is rolled back.
+// When ctx comes from a Tx of this client (see WithTx), callback runs in a savepoint of that transaction instead.
+func (mc *MysqlClient) TransactionContext(ctx context.Context, callback TransactionCallback, opts ...TxOption) error {
+	if parent, ok := mc.txFromContext(ctx); ok {
+		return parent.savepoint(ctx, func(tx *Tx) error {
+			return callback(tx.Tx)
+		})
+	}
+	return mc.transaction(ctx, newTxConfig(opts), func(ctx context.Context, tx *sql.Tx) error {
+		return callback(tx)
+	})
+}
+
+func (mc *MysqlClient) transaction(ctx context.Context, config *txConfig, callback func(context.Context, *sql.Tx) error) error {
+	if config.retry == nil {
+		return mc.transactionOnce(ctx, config, callback)
+	}
+	return config.retry.run(ctx, mc.mapper().getLogger(), func() error {
+		return mc.transactionOnce(ctx, config, callback)
+	})
+}
+
+func (mc *MysqlClient) transactionOnce(ctx context.Context, config *txConfig, callback func(context.Context, *sql.Tx) error) error {
+	if config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.timeout)
+		defer cancel()
+	}
+	tx, end, err := mc.beginTx(ctx, config)
 	if err != nil {
 		return err
 	}
-	err = callback(tx)
+	defer end()
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	err = callback(ctx, tx)
 	if err != nil {
-		tx.Rollback()
+		rollbackErr := tx.Rollback()
+		if errors.Is(rollbackErr, sql.ErrTxDone) {
+			// a done ctx rolls the transaction back by itself; otherwise the callback ended it
+			if ctx.Err() != nil {
+				return err
+			}
+			return errors.Join(err, TxDoneError)
+		}
+		if rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
 		return err
 	}
-	return tx.Commit()
+	err = tx.Commit()
+	if errors.Is(err, sql.ErrTxDone) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return TxDoneError
+	}
+	return err
 }
 
 func (mc *MysqlClient) Count(sql string, args ...interface{}) (int64, error) {
-	tx, err := mc.GetTransaction()
-	if err != nil {
-		return 0, err
-	}
-	defer tx.Commit()
-	var count int64
-	err = tx.QueryRow(sql, args...).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-	return count, nil
+	return mc.CountContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) CountContext(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return QueryScalarContext[int64](ctx, mc, sql, args...)
 }
 
 type FieldFunc func(rows *sql.Rows) error
 
 func (mc *MysqlClient) FindCustom(query string, fieldFunc FieldFunc, args ...interface{}) error {
-	rows, err := mc.GetDB().Query(query, args...)
+	return mc.FindCustomContext(context.Background(), query, fieldFunc, args...)
+}
+
+func (mc *MysqlClient) FindCustomContext(ctx context.Context, query string, fieldFunc FieldFunc, args ...interface{}) error {
+	query, args, err := mc.getConverters().bind(query, args)
+	if err != nil {
+		return err
+	}
+	rows, err := mc.session(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -89,60 +151,101 @@ func (mc *MysqlClient) FindCustom(query string, fieldFunc FieldFunc, args ...int
 	return rows.Err()
 }
 
+// Find decodes every row into input, a pointer to a slice of structs or struct pointers.
+// For a struct with a softdelete field, the condition excluding deleted rows is ANDed into the WHERE clause
+// of a single-table SELECT; queries with joins, unions or derived tables return an error and must filter
+// deleted rows themselves on an Unscoped client, which sees every row.
 func (mc *MysqlClient) Find(sql string, input interface{}, args ...interface{}) error {
-	//json.Marshal()
-	rows, err := mc.GetDB().Query(sql, args...)
+	return mc.FindContext(context.Background(), sql, input, args...)
+}
+
+func (mc *MysqlClient) FindContext(ctx context.Context, sql string, input interface{}, args ...interface{}) error {
+	if !isSlicePtr(input) {
+		return fmt.Errorf("%v must be a slice pointer", input)
+	}
+	sql, args, err := mc.getConverters().bind(sql, args)
 	if err != nil {
 		return err
 	}
+	sql, err = mc.scoped(sql, reflect.TypeOf(input).Elem().Elem())
+	if err != nil {
+		return err
+	}
+	rows, err := mc.session(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanRows(rows, input, mc.mapper())
+}
+
+func (mc *MysqlClient) FindMapArray(sql string, args ...interface{}) ([]map[string]interface{}, error) {
+	return mc.FindMapArrayContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) FindMapArrayContext(ctx context.Context, sql string, args ...interface{}) ([]map[string]interface{}, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := mc.session(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
-	colNames, err := rows.Columns()
+	return scanMaps(rows)
+}
+
+func (mc *MysqlClient) FindMapFirst(sql string, args ...interface{}) (map[string]interface{}, error) {
+	return mc.FindMapFirstContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) FindMapFirstContext(ctx context.Context, sql string, args ...interface{}) (map[string]interface{}, error) {
+	array, err := mc.FindMapArrayContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(array) == 0 {
+		return nil, RecordNotFoundError
+	}
+	return array[0], nil
+}
+
+// FindFirst decodes the first row into input, a struct pointer, leaving out soft-deleted rows like Find.
+func (mc *MysqlClient) FindFirst(sql string, input interface{}, args ...interface{}) error {
+	return mc.findFirst(context.Background(), sql, input, false, args...)
+}
+
+func (mc *MysqlClient) FindFirstContext(ctx context.Context, sql string, input interface{}, args ...interface{}) error {
+	return mc.findFirst(ctx, sql, input, false, args...)
+}
+
+// FindOne is FindFirst for lookups that must match at most one row; a second row returns TooManyRowsError.
+func (mc *MysqlClient) FindOne(sql string, input interface{}, args ...interface{}) error {
+	return mc.findFirst(context.Background(), sql, input, true, args...)
+}
+
+func (mc *MysqlClient) FindOneContext(ctx context.Context, sql string, input interface{}, args ...interface{}) error {
+	return mc.findFirst(ctx, sql, input, true, args...)
+}
+
+func (mc *MysqlClient) findFirst(ctx context.Context, sql string, input interface{}, single bool, args ...interface{}) error {
+	inputT := reflect.TypeOf(input)
+	if inputT == nil || !isStructPtr(inputT) || !isMappableStruct(inputT.Elem()) {
+		return fmt.Errorf("%v must be a struct pointer", input)
+	}
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		return err
+	}
+	sql, err = mc.scoped(sql, inputT.Elem())
 	if err != nil {
 		return err
 	}
-	cols := make([]interface{}, len(colNames))
-	colPtrs := make([]interface{}, len(colNames))
-	for i := 0; i < len(colNames); i++ {
-		colPtrs[i] = &cols[i]
+	rows, err := mc.session(ctx).QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
 	}
-	var array []map[string]interface{}
-	var myMap = make(map[string]interface{})
-	for rows.Next() {
-		err = rows.Scan(colPtrs...)
-		if err != nil {
-			return err
-		}
-		for i, col := range cols {
-			myMap[colNames[i]] = col
-		}
-		array = append(array, myMap)
-		//for key, val := range myMap {
-		//	fmt.Println("Key:", key, "Value Type:", reflect.TypeOf(val))
-		//}
-	}
-	return nil
-}
-
-//func (mc *MysqlClient) FindFirst(sql string, input interface{}, args ...interface{}) error {
-//	if isStruct(input) {
-//		return fmt.Errorf("%v must be a struct or a struct pointer", input)
-//	}
-//	result, err := mc.FindMapFirst(sql, args...)
-//	if err != nil {
-//		return err
-//	}
-//	config := &mapstructure.DecoderConfig{
-//		DecodeHook:       mapstructure.StringToTimeHookFunc("2006-01-02T15:04:05Z07:00"),
-//		WeaklyTypedInput: true,
-//		Result:           input,
-//	}
-//	decoder, err := mapstructure.NewDecoder(config)
-//	if err != nil {
-//		return err
-//	}
-//	err = decoder.Decode(result)
-//	if err != nil {
-//		return err
-//	}
-//	return nil
-//}
+	defer rows.Close()
+	return scanFirst(rows, input, single, mc.mapper())
+}
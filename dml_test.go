@@ -1,6 +1,8 @@
 package mysqlclient
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/sillyhatxu/mysql-client/example/model"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -18,3 +20,61 @@ func TestMysqlClient_Find(t *testing.T) {
 	assert.Nil(t, err)
 	assert.EqualValues(t, 1, len(userArray))
 }
+
+func TestMysqlClient_FindFirst(t *testing.T) {
+	once.Do(setup)
+	var user model.User
+	err := mysqlClient.FindFirst("select * from user order by id", &user)
+	assert.Nil(t, err)
+	err = mysqlClient.FindFirst("select * from user where id = ?", &user, -1)
+	assert.True(t, errors.Is(err, RecordNotFoundError))
+}
+
+func TestFindFirstRequiresStructPointer(t *testing.T) {
+	mc := &MysqlClient{}
+	var user model.User
+	assert.NotNil(t, mc.FindFirst("select * from user", user))
+	var users []model.User
+	assert.NotNil(t, mc.FindOne("select * from user", &users))
+}
+
+func TestMysqlClient_FindMapArray(t *testing.T) {
+	once.Do(setup)
+	results, err := mysqlClient.FindMapArray("select * from user")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(results))
+}
+
+// BenchmarkMysqlClient_Find and BenchmarkMysqlClient_RowsScan read the same columns into model.User,
+// through the mapper and through a hand-written rows.Scan.
+const benchmarkUserSQL = "select id, name from user"
+
+func BenchmarkMysqlClient_Find(b *testing.B) {
+	once.Do(setup)
+	for i := 0; i < b.N; i++ {
+		var userArray []model.User
+		err := mysqlClient.Find(benchmarkUserSQL, &userArray)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMysqlClient_RowsScan(b *testing.B) {
+	once.Do(setup)
+	for i := 0; i < b.N; i++ {
+		var userArray []model.User
+		err := mysqlClient.FindCustom(benchmarkUserSQL, func(rows *sql.Rows) error {
+			var u model.User
+			err := rows.Scan(&u.Id, &u.Name)
+			if err != nil {
+				return err
+			}
+			userArray = append(userArray, u)
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package mysqlclient
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	RecordNotFoundError = errors.New("record not found")
+
+	TooManyRowsError = errors.New("query returned more than one row")
+
+	// TxDoneError is returned by Transaction when the callback committed or rolled back the transaction itself.
+	TxDoneError = errors.New("transaction was already committed or rolled back by the callback")
+)
+
+// MappingError lists result columns without a struct field and tagged fields without a result column.
+type MappingError struct {
+	Type    reflect.Type
+	Columns []string
+	Fields  []string
+}
+
+func (e *MappingError) Error() string {
+	var parts []string
+	if len(e.Columns) > 0 {
+		parts = append(parts, fmt.Sprintf("columns without field: %s", strings.Join(e.Columns, ", ")))
+	}
+	if len(e.Fields) > 0 {
+		parts = append(parts, fmt.Sprintf("fields without column: %s", strings.Join(e.Fields, ", ")))
+	}
+	return fmt.Sprintf("mapping %v: %s", e.Type, strings.Join(parts, "; "))
+}
+
+// BatchError reports the item of a batch that failed.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// VersionConflictError is returned by UpdateStruct when no row has the expected version,
+// because another writer updated (or deleted) the row first.
+type VersionConflictError struct {
+	Table   string
+	Version int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s: row was modified concurrently, version %d is stale", e.Table, e.Version)
+}
@@ -0,0 +1,158 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ExecResult is the outcome of one statement run by Exec or ExecWarnings.
+type ExecResult struct {
+	LastInsertId int64
+	RowsAffected int64
+	Duration     time.Duration
+	// Warnings is only filled by ExecWarnings
+	Warnings []Warning
+}
+
+// Warning is one row of SHOW WARNINGS, e.g. {Warning 1265 Data truncated for column 'name' at row 1}.
+type Warning struct {
+	Level   string
+	Code    int
+	Message string
+}
+
+// Exec runs a statement through the prepared statement cache and returns both LastInsertId and RowsAffected.
+// A slice argument filling an IN (?) and longer than the InListSize option runs the statement once per chunk inside a transaction,
+// or a savepoint of the one carried by ctx; the result then sums RowsAffected and keeps the last LastInsertId.
+func (mc *MysqlClient) Exec(sql string, args ...interface{}) (*ExecResult, error) {
+	return mc.ExecContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) ExecContext(ctx context.Context, sql string, args ...interface{}) (*ExecResult, error) {
+	inListSize := 1000
+	if mc.config != nil {
+		inListSize = mc.config.inListSize
+	}
+	// resolve :name placeholders first so that a long named list can be chunked too
+	sql, args, err := resolveNamed(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	// encode before splitting, so that a Set or other encoded slice is never taken for an IN list
+	args, err = mc.getConverters().encodeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if chunks := splitInArgs(sql, args, inListSize); len(chunks) > 1 {
+		return mc.execChunks(ctx, sql, chunks)
+	}
+	sql, args, err = mc.getConverters().bind(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	startT := time.Now()
+	stm, release, err := mc.prepare(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	result, err := stm.ExecContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newExecResult(result, startT)
+}
+
+// ExecWarnings is Exec followed by SHOW WARNINGS on the same connection, so that notes such as
+// truncated or out-of-range values are reported instead of dropped. It bypasses the statement cache.
+func (mc *MysqlClient) ExecWarnings(sql string, args ...interface{}) (*ExecResult, error) {
+	return mc.ExecWarningsContext(context.Background(), sql, args...)
+}
+
+func (mc *MysqlClient) ExecWarningsContext(ctx context.Context, sql string, args ...interface{}) (*ExecResult, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	// both statements must reach the same connection: the transaction's, or one taken from the pool
+	var conn sqlSession
+	if tx, ok := mc.txFromContext(ctx); ok {
+		conn = tx.Tx
+	} else {
+		poolConn, err := mc.GetDB().Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer poolConn.Close()
+		conn = poolConn
+	}
+	startT := time.Now()
+	result, err := conn.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	execResult, err := newExecResult(result, startT)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var warning Warning
+		err = rows.Scan(&warning.Level, &warning.Code, &warning.Message)
+		if err != nil {
+			return nil, err
+		}
+		execResult.Warnings = append(execResult.Warnings, warning)
+	}
+	return execResult, rows.Err()
+}
+
+func (mc *MysqlClient) execChunks(ctx context.Context, query string, chunks [][]interface{}) (*ExecResult, error) {
+	startT := time.Now()
+	execResult := &ExecResult{}
+	err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		for _, args := range chunks {
+			chunkSQL, args, err := mc.getConverters().bind(query, args)
+			if err != nil {
+				return err
+			}
+			result, err := tx.ExecContext(ctx, chunkSQL, args...)
+			if err != nil {
+				return err
+			}
+			chunkResult, err := newExecResult(result, startT)
+			if err != nil {
+				return err
+			}
+			execResult.LastInsertId = chunkResult.LastInsertId
+			execResult.RowsAffected += chunkResult.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	execResult.Duration = time.Since(startT)
+	return execResult, nil
+}
+
+func newExecResult(result sql.Result, startT time.Time) (*ExecResult, error) {
+	lastInsertId, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	return &ExecResult{
+		LastInsertId: lastInsertId,
+		RowsAffected: rowsAffected,
+		Duration:     time.Since(startT),
+	}, nil
+}
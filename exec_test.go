@@ -0,0 +1,45 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	db, _ := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}, stmts: newStmtCache(10)}
+	result, err := mc.Exec("update userinfo set name = ? where id = ?", "test name", 7)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 7, result.LastInsertId)
+	assert.EqualValues(t, 1, result.RowsAffected)
+	assert.Nil(t, result.Warnings)
+
+	rowsAffected, err := mc.Update("update userinfo set name = ? where id = ?", "test name", 7)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, rowsAffected)
+	assert.EqualValues(t, 1, mc.StmtCacheStats().Hits)
+}
+
+func TestMysqlClient_ExecWarnings(t *testing.T) {
+	once.Do(setup)
+	result, err := mysqlClient.ExecWarnings("select cast('12abc' as signed)")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result.Warnings))
+	assert.EqualValues(t, 1292, result.Warnings[0].Code)
+}
+
+func TestExecContext_Canceled(t *testing.T) {
+	db, _ := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}, stmts: newStmtCache(10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := mc.UpdateContext(ctx, "update userinfo set name = ? where id = ?", "test name", 7)
+	assert.ErrorIs(t, err, context.Canceled)
+	err = mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, mc.PingContext(ctx), context.Canceled)
+}
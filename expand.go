@@ -0,0 +1,252 @@
+package mysqlclient
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// emptyIn replaces the placeholder of an empty slice: x IN (empty) is false and x NOT IN (empty) is true,
+// where IN (NULL) would make both NULL.
+const emptyIn = "SELECT NULL FROM DUAL WHERE FALSE"
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// bind encodes args and expands every slice argument into one placeholder per element,
+// so that `id IN (?)` accepts []int64{1, 2, 3}. A single map or struct argument binds :name placeholders.
+func (r *converterRegistry) bind(query string, args []interface{}) (string, []interface{}, error) {
+	if len(args) == 1 {
+		named, namedArgs, ok, err := bindNamed(query, args[0])
+		if err != nil {
+			return "", nil, err
+		}
+		if ok {
+			query, args = named, namedArgs
+		}
+	}
+	args, err := r.encodeArgs(args)
+	if err != nil {
+		return "", nil, err
+	}
+	expanded, expandedArgs, err := expandArgs(query, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(expandedArgs) == len(args) {
+		return expanded, expandedArgs, nil
+	}
+	// the elements of expanded slices may need encoding too
+	expandedArgs, err = r.encodeArgs(expandedArgs)
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, expandedArgs, nil
+}
+
+// expandable reports whether arg is a slice bound to a single placeholder.
+// []byte is a BLOB value and driver.Valuer types encode themselves.
+func expandable(arg interface{}) (reflect.Value, bool) {
+	if arg == nil {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 || v.Type().Implements(valuerType) {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+func expandArgs(query string, args []interface{}) (string, []interface{}, error) {
+	expand := false
+	for _, arg := range args {
+		if _, ok := expandable(arg); ok {
+			expand = true
+			break
+		}
+	}
+	if !expand {
+		return query, args, nil
+	}
+	positions := placeholders(query)
+	if len(positions) != len(args) {
+		return "", nil, fmt.Errorf("query has %d placeholders for %d arguments", len(positions), len(args))
+	}
+	var sb strings.Builder
+	var expandedArgs []interface{}
+	last := 0
+	for i, arg := range args {
+		v, ok := expandable(arg)
+		if !ok {
+			expandedArgs = append(expandedArgs, arg)
+			continue
+		}
+		pos := positions[i]
+		sb.WriteString(query[last:pos])
+		last = pos + 1
+		if v.Len() == 0 {
+			if enclosed(query, pos) {
+				sb.WriteString(emptyIn)
+			} else {
+				sb.WriteString("(" + emptyIn + ")")
+			}
+			continue
+		}
+		sb.WriteString(strings.TrimSuffix(strings.Repeat("?, ", v.Len()), ", "))
+		for j := 0; j < v.Len(); j++ {
+			expandedArgs = append(expandedArgs, v.Index(j).Interface())
+		}
+	}
+	sb.WriteString(query[last:])
+	return sb.String(), expandedArgs, nil
+}
+
+// enclosed reports whether the placeholder at pos is the only thing between a pair of parentheses, as in IN (?).
+func enclosed(query string, pos int) bool {
+	before := strings.TrimRight(query[:pos], " \t\r\n")
+	after := strings.TrimLeft(query[pos+1:], " \t\r\n")
+	return strings.HasSuffix(before, "(") && strings.HasPrefix(after, ")")
+}
+
+// placeholders returns the offsets of the ? placeholders in query, skipping string literals,
+// quoted identifiers and comments.
+func placeholders(query string) []int {
+	var positions []int
+	walkSQL(query, func(i int) int {
+		if query[i] == '?' {
+			positions = append(positions, i)
+		}
+		return i
+	})
+	return positions
+}
+
+// walkSQL calls visit with the offset of every byte of query outside string literals, quoted identifiers
+// and comments. visit returns the offset of the last byte it consumed.
+func walkSQL(query string, visit func(i int) int) {
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+		case c == '#' || isDashComment(query, i):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return
+			}
+			i += end + 3
+		default:
+			i = visit(i)
+		}
+	}
+}
+
+// isDashComment reports whether a -- comment starts at i; MySQL requires whitespace after the dashes.
+func isDashComment(query string, i int) bool {
+	if !strings.HasPrefix(query[i:], "--") {
+		return false
+	}
+	return i+2 == len(query) || strings.IndexByte(" \t\r\n", query[i+2]) >= 0
+}
+
+// skipQuoted returns the offset of the quote closing the literal or identifier opened at start.
+// A doubled quote or, outside identifiers, a backslash escapes the next character.
+func skipQuoted(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query)
+}
+
+// splitInArgs splits args into chunks when exactly one slice argument holds more than size elements
+// and fills a positive IN (?) of query, so that DELETE ... WHERE id IN (?) can run once per chunk.
+// A list under NOT IN, or in a statement that may negate it, is bound whole: running such a statement
+// per chunk would touch the rows the other chunks meant to exclude.
+func splitInArgs(query string, args []interface{}, size int) [][]interface{} {
+	if size < 1 {
+		return [][]interface{}{args}
+	}
+	large := -1
+	for i, arg := range args {
+		v, ok := expandable(arg)
+		if !ok || v.Len() <= size {
+			continue
+		}
+		if large >= 0 {
+			return [][]interface{}{args}
+		}
+		large = i
+	}
+	if large < 0 {
+		return [][]interface{}{args}
+	}
+	positions := placeholders(query)
+	if len(positions) != len(args) || !positiveIn(query, positions[large]) || mayNegate(query) {
+		return [][]interface{}{args}
+	}
+	v := reflect.ValueOf(args[large])
+	var chunks [][]interface{}
+	for start := 0; start < v.Len(); start += size {
+		end := start + size
+		if end > v.Len() {
+			end = v.Len()
+		}
+		chunk := append([]interface{}(nil), args...)
+		chunk[large] = v.Slice(start, end).Interface()
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// positiveIn reports whether the placeholder at pos is the whole list of an IN (?) that is not a NOT IN.
+func positiveIn(query string, pos int) bool {
+	if !enclosed(query, pos) {
+		return false
+	}
+	before := strings.TrimRight(query[:pos], " \t\r\n")
+	words := strings.Fields(strings.TrimSuffix(before, "("))
+	if len(words) == 0 || !strings.EqualFold(words[len(words)-1], "IN") {
+		return false
+	}
+	return len(words) < 2 || !strings.EqualFold(words[len(words)-2], "NOT")
+}
+
+// mayNegate reports whether query has a NOT (...) or ! operator that could negate a whole IN predicate.
+func mayNegate(query string) bool {
+	negate := false
+	walkSQL(query, func(i int) int {
+		c := query[i]
+		if c == '!' && (i+1 == len(query) || query[i+1] != '=') {
+			negate = true
+		}
+		if !isNameStart(c) || (i > 0 && isWordByte(query[i-1])) {
+			return i
+		}
+		end := i + 1
+		for end < len(query) && isWordByte(query[end]) {
+			end++
+		}
+		if strings.EqualFold(query[i:end], "NOT") && strings.HasPrefix(strings.TrimLeft(query[end:], " \t\r\n"), "(") {
+			negate = true
+		}
+		return end - 1
+	})
+	return negate
+}
@@ -0,0 +1,82 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	assert.EqualValues(t, []int{33, 45}, placeholders("select * from userinfo where a = ? and b in (?)"))
+	assert.EqualValues(t, []int{44}, placeholders("select '?', \"it\\\"s ?\", `?` from t where a = ?"))
+	assert.EqualValues(t, []int{43}, placeholders("select 1 -- ?\n/* ? */ # ?\nfrom t where a = ?"))
+	assert.EqualValues(t, []int{23}, placeholders("select 'it''s ?' where ?"))
+	assert.EqualValues(t, []int{11}, placeholders("select 1--1?"))
+}
+
+func TestExpandArgs(t *testing.T) {
+	query, args, err := expandArgs("delete from userinfo where id in (?) and name = ?", []interface{}{[]int64{1, 2, 3}, "test name"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "delete from userinfo where id in (?, ?, ?) and name = ?", query)
+	assert.EqualValues(t, []interface{}{int64(1), int64(2), int64(3), "test name"}, args)
+
+	query, args, err = expandArgs("select * from userinfo where id not in ( ? )", []interface{}{[]int{}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "select * from userinfo where id not in ( SELECT NULL FROM DUAL WHERE FALSE )", query)
+	assert.EqualValues(t, 0, len(args))
+
+	query, _, err = expandArgs("select * from userinfo where id in ?", []interface{}{[]int{}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "select * from userinfo where id in (SELECT NULL FROM DUAL WHERE FALSE)", query)
+
+	query, args, err = expandArgs("insert into userinfo (content) values (?)", []interface{}{[]byte("abc")})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "insert into userinfo (content) values (?)", query)
+	assert.EqualValues(t, []interface{}{[]byte("abc")}, args)
+
+	_, _, err = expandArgs("select * from userinfo where id in (?)", []interface{}{[]int{1}, 2})
+	assert.NotNil(t, err)
+}
+
+func TestBind(t *testing.T) {
+	query, args, err := defaultConverters.bind("update userinfo set tags = ? where id in (?)", []interface{}{Set{"a", "b"}, []int{1, 2}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "update userinfo set tags = ? where id in (?, ?)", query)
+	assert.EqualValues(t, []interface{}{"a,b", 1, 2}, args)
+}
+
+func TestSplitInArgs(t *testing.T) {
+	query := "delete from userinfo where name = ? and id in (?)"
+	chunks := splitInArgs(query, []interface{}{"test name", []int{1, 2, 3, 4, 5}}, 2)
+	assert.EqualValues(t, [][]interface{}{
+		{"test name", []int{1, 2}},
+		{"test name", []int{3, 4}},
+		{"test name", []int{5}},
+	}, chunks)
+	assert.EqualValues(t, 1, len(splitInArgs("delete from userinfo where id in (?)", []interface{}{[]int{1, 2}}, 2)))
+	assert.EqualValues(t, 1, len(splitInArgs("delete from userinfo where id in (?) or id in (?)", []interface{}{[]int{1, 2, 3}, []int{1, 2, 3}}, 2)))
+	assert.EqualValues(t, 1, len(splitInArgs("delete from userinfo where id not in (?)", []interface{}{[]int{1, 2, 3}}, 2)))
+	assert.EqualValues(t, 1, len(splitInArgs("delete from userinfo where not (id in (?))", []interface{}{[]int{1, 2, 3}}, 2)))
+	assert.EqualValues(t, 1, len(splitInArgs("insert into userinfo (ids) values (?)", []interface{}{[]int{1, 2, 3}}, 2)))
+	assert.EqualValues(t, 2, len(splitInArgs("delete from userinfo where name != ? and id IN ( ? )", []interface{}{"x", []int{1, 2, 3}}, 2)))
+}
+
+func TestExec_NotInNotChunked(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db, inListSize: 2}, stmts: newStmtCache(10)}
+	_, err := mc.Delete("delete from userinfo where id not in (?)", []int64{1, 2, 3})
+	assert.Nil(t, err)
+	_, err = mc.Delete("delete from userinfo where tags in (?)", Set{"a", "b", "c"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"delete from userinfo where id not in (?, ?, ?)",
+		"delete from userinfo where tags in (?)",
+	}, d.queries)
+}
+
+func TestExec_Chunks(t *testing.T) {
+	db, _ := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db, inListSize: 2}, stmts: newStmtCache(10)}
+	rowsAffected, err := mc.Delete("delete from userinfo where id in (?)", []int64{1, 2, 3, 4, 5})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, rowsAffected)
+}
@@ -1,6 +1,7 @@
 package mysqlclient
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"hash/fnv"
@@ -43,15 +44,15 @@ type SchemaVersion struct {
 	CreatedTime   *time.Time
 }
 
-func (mc *MysqlClient) initialFlayway() (err error) {
+func (mc *MysqlClient) initialFlayway(ctx context.Context) (err error) {
 	if !mc.config.flyway {
 		return nil
 	}
-	err = mc.initialSchemaVersion()
+	err = mc.initialSchemaVersion(ctx)
 	if err != nil {
 		return err
 	}
-	err = mc.executeFlayway()
+	err = mc.executeFlayway(ctx)
 	if err != nil {
 		return err
 	}
@@ -59,8 +60,12 @@ func (mc *MysqlClient) initialFlayway() (err error) {
 }
 
 func (mc *MysqlClient) ExecDDL(ddl string) error {
+	return mc.ExecDDLContext(context.Background(), ddl)
+}
+
+func (mc *MysqlClient) ExecDDLContext(ctx context.Context, ddl string) error {
 	startT := time.Now()
-	result, err := mc.GetDB().Exec(ddl)
+	result, err := mc.GetDB().ExecContext(ctx, ddl)
 	if err != nil {
 		return err
 	}
@@ -68,7 +73,7 @@ func (mc *MysqlClient) ExecDDL(ddl string) error {
 	if err != nil {
 		return err
 	}
-	rowsAffected, err := result.LastInsertId()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
@@ -77,12 +82,12 @@ func (mc *MysqlClient) ExecDDL(ddl string) error {
 	return nil
 }
 
-func (mc *MysqlClient) executeFlayway() error {
+func (mc *MysqlClient) executeFlayway(ctx context.Context) error {
 	files, err := ioutil.ReadDir(mc.config.ddlPath)
 	if err != nil {
 		return nil
 	}
-	svArray, err := mc.SchemaVersionArray()
+	svArray, err := mc.SchemaVersionArrayContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -91,7 +96,7 @@ func (mc *MysqlClient) executeFlayway() error {
 		return err
 	}
 	for _, f := range files {
-		err := mc.readFile(f, svArray)
+		err := mc.readFile(ctx, f, svArray)
 		if err != nil {
 			return err
 		}
@@ -108,7 +113,7 @@ func hash64(s string) (uint64, error) {
 	return h.Sum64(), nil
 }
 
-func (mc *MysqlClient) readFile(fileInfo os.FileInfo, svArray []SchemaVersion) error {
+func (mc *MysqlClient) readFile(ctx context.Context, fileInfo os.FileInfo, svArray []SchemaVersion) error {
 	b, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", mc.config.ddlPath, fileInfo.Name()))
 	if err != nil {
 		return err
@@ -130,7 +135,7 @@ func (mc *MysqlClient) readFile(fileInfo os.FileInfo, svArray []SchemaVersion) e
 		Checksum: strconv.FormatUint(checksum, 10),
 		Status:   schemaVersionStatusError,
 	}
-	err = mc.ExecDDL(string(b))
+	err = mc.ExecDDLContext(ctx, string(b))
 	if err == nil {
 		schemaVersion.Status = schemaVersionStatusSuccess
 	}
@@ -180,8 +185,12 @@ func (mc *MysqlClient) hasError(svArray []SchemaVersion) error {
 }
 
 func (mc *MysqlClient) SchemaVersionArray() ([]SchemaVersion, error) {
+	return mc.SchemaVersionArrayContext(context.Background())
+}
+
+func (mc *MysqlClient) SchemaVersionArrayContext(ctx context.Context) ([]SchemaVersion, error) {
 	var svArray []SchemaVersion
-	err := mc.FindCustom(`select * from schema_version`, func(rows *sql.Rows) error {
+	err := mc.FindCustomContext(ctx, `select * from schema_version`, func(rows *sql.Rows) error {
 		var sv SchemaVersion
 		err := rows.Scan(&sv.Id, &sv.Script, &sv.Checksum, &sv.ExecutionTime, &sv.Status, &sv.CreatedTime)
 		svArray = append(svArray, sv)
@@ -196,24 +205,29 @@ func (mc *MysqlClient) SchemaVersionArray() ([]SchemaVersion, error) {
 	return svArray, nil
 }
 
-func (mc *MysqlClient) initialSchemaVersion() error {
-	exist, err := mc.HasTable("schema_version")
+func (mc *MysqlClient) initialSchemaVersion(ctx context.Context) error {
+	exist, err := mc.HasTableContext(ctx, "schema_version")
 	if err != nil {
 		return err
 	}
 	if exist {
 		return nil
 	}
-	return mc.ExecDDL(ddlSchemaVersion)
+	return mc.ExecDDLContext(ctx, ddlSchemaVersion)
 }
 
 func (mc *MysqlClient) HasTable(tableName string) (bool, error) {
-	_, err := mc.GetDB().Query(fmt.Sprintf("SELECT 1 FROM %s LIMIT 1", tableName))
+	return mc.HasTableContext(context.Background(), tableName)
+}
+
+func (mc *MysqlClient) HasTableContext(ctx context.Context, tableName string) (bool, error) {
+	rows, err := mc.GetDB().QueryContext(ctx, fmt.Sprintf("SELECT 1 FROM %s LIMIT 1", tableName))
 	if err != nil {
 		if strings.HasSuffix(err.Error(), "doesn't exist") {
 			return false, nil
 		}
 		return true, err
 	}
+	rows.Close()
 	return true, nil
 }
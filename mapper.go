@@ -0,0 +1,461 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldsCache and columnsCache hold structFields and cachedStructColumns per struct type;
+// the values are never modified once stored.
+var (
+	fieldsCache sync.Map
+
+	columnsCache sync.Map
+)
+
+// columnName returns the column a struct field is mapped to and whether it came from a tag.
+// The `db` tag wins over the `mysql` tag, otherwise the field name is converted to snake_case.
+// A tag of "-" skips the field.
+func columnName(field reflect.StructField) (name string, tagged bool, ok bool) {
+	for _, key := range []string{"db", "mysql"} {
+		tag, exist := field.Tag.Lookup(key)
+		if !exist {
+			continue
+		}
+		name := strings.TrimSpace(strings.Split(tag, ",")[0])
+		if name == "-" {
+			return "", true, false
+		}
+		if name != "" {
+			return name, true, true
+		}
+	}
+	return toSnakeCase(field.Name), false, true
+}
+
+func hasTagOption(field reflect.StructField, option string) bool {
+	for _, key := range []string{"db", "mysql"} {
+		tag, exist := field.Tag.Lookup(key)
+		if !exist {
+			continue
+		}
+		for _, opt := range strings.Split(tag, ",")[1:] {
+			if strings.TrimSpace(opt) == option {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// fieldInfo describes a mapped field. Tag options follow the column name, as in db:"id,pk,autoincr".
+type fieldInfo struct {
+	// name is the lower-cased lookup key, column keeps the spelling used in the tag
+	name   string
+	column string
+	index  []int
+	// tagged fields are expected to have a column in strict mapping mode
+	tagged bool
+	// json encodes and decodes the column with encoding/json
+	json bool
+	// pk marks the primary key; autoincr fields are left out of INSERTs while zero
+	// and receive LastInsertId, as does a lone integer pk when no field is tagged autoincr;
+	// omitempty fields are left out of INSERTs while zero
+	pk        bool
+	autoIncr  bool
+	omitEmpty bool
+	// version is the optimistic locking counter checked and incremented by UpdateStruct
+	version bool
+	// softDelete is the deleted flag or deleted_at timestamp set by DeleteStruct and filtered by struct finds
+	softDelete bool
+}
+
+// structFields maps lower-cased column names onto the field they are decoded into.
+// Untagged embedded structs are flattened. Any other struct field is nested under its column name as a prefix,
+// so with ColumnsWithAlias a field tagged db:"u" receives the columns u.id, u.name and so on.
+func structFields(t reflect.Type) map[string]fieldInfo {
+	fields := make(map[string]fieldInfo)
+	collectFields(t, "", nil, fields, map[reflect.Type]bool{})
+	return fields
+}
+
+func cachedStructFields(t reflect.Type) map[string]fieldInfo {
+	fields, ok := fieldsCache.Load(t)
+	if !ok {
+		fields, _ = fieldsCache.LoadOrStore(t, structFields(t))
+	}
+	return fields.(map[string]fieldInfo)
+}
+
+// cachedStructColumns lists the top-level (not prefixed) fields of t in declaration order,
+// which is the column order used when building statements from a struct.
+// A single integer pk is treated as autoincr unless another field carries the tag.
+func cachedStructColumns(t reflect.Type) []fieldInfo {
+	columns, ok := columnsCache.Load(t)
+	if ok {
+		return columns.([]fieldInfo)
+	}
+	var list []fieldInfo
+	for _, info := range cachedStructFields(t) {
+		if !strings.Contains(info.name, ".") {
+			list = append(list, info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return lessIndex(list[i].index, list[j].index)
+	})
+	implicitAutoIncr(t, list)
+	columns, _ = columnsCache.LoadOrStore(t, list)
+	return columns.([]fieldInfo)
+}
+
+func implicitAutoIncr(t reflect.Type, list []fieldInfo) {
+	pk := -1
+	for i, info := range list {
+		if info.autoIncr {
+			return
+		}
+		if info.pk {
+			if pk >= 0 {
+				return
+			}
+			pk = i
+		}
+	}
+	if pk < 0 {
+		return
+	}
+	switch t.FieldByIndex(list[pk].index).Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		list[pk].autoIncr = true
+	}
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func collectFields(t reflect.Type, prefix string, index []int, fields map[string]fieldInfo, visited map[reflect.Type]bool) {
+	visited[t] = true
+	defer delete(visited, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if field.PkgPath != "" && field.Type.Kind() == reflect.Ptr {
+			// like encoding/json: a nil pointer to an unexported embedded struct can not be allocated
+			continue
+		}
+		name, tagged, ok := columnName(field)
+		if !ok {
+			continue
+		}
+		path := append(index[:len(index):len(index)], i)
+		fieldT := field.Type
+		if fieldT.Kind() == reflect.Ptr {
+			fieldT = fieldT.Elem()
+		}
+		jsonField := hasTagOption(field, "json")
+		if isMappableStruct(fieldT) && !jsonField {
+			if visited[fieldT] {
+				continue
+			}
+			nestedPrefix := prefix
+			if !field.Anonymous || tagged {
+				nestedPrefix = prefix + name + "."
+			}
+			collectFields(fieldT, nestedPrefix, path, fields, visited)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		key := strings.ToLower(prefix + name)
+		// like Go's field promotion, the shallower field wins
+		if existing, exist := fields[key]; !exist || len(path) < len(existing.index) {
+			fields[key] = fieldInfo{
+				name:       key,
+				column:     prefix + name,
+				index:      path,
+				tagged:     tagged,
+				json:       jsonField,
+				pk:         hasTagOption(field, "pk"),
+				autoIncr:   hasTagOption(field, "autoincr"),
+				omitEmpty:  hasTagOption(field, "omitempty"),
+				version:    hasTagOption(field, "version"),
+				softDelete: hasTagOption(field, "softdelete"),
+			}
+		}
+	}
+}
+
+// lookupField finds the field for a result column. Alias-qualified columns such as u.id
+// fall back to the bare column name when the struct has no matching nested field.
+func lookupField(fields map[string]fieldInfo, colName string) (fieldInfo, bool) {
+	key := strings.ToLower(colName)
+	field, ok := fields[key]
+	if ok {
+		return field, true
+	}
+	dot := strings.LastIndex(key, ".")
+	if dot < 0 {
+		return fieldInfo{}, false
+	}
+	field, ok = fields[key[dot+1:]]
+	return field, ok
+}
+
+// fieldByIndex walks the index path from a struct value. Nil nested struct pointers are allocated
+// only when alloc is set, which keeps a LEFT JOIN's nested struct nil while all its columns are NULL.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// mapper carries the per-client settings used to decode rows into structs.
+type mapper struct {
+	converters *converterRegistry
+	mode       MappingMode
+	logger     Logger
+}
+
+func (mc *MysqlClient) mapper() *mapper {
+	m := &mapper{converters: mc.getConverters(), mode: MappingIgnore}
+	if mc.config != nil {
+		m.mode = mc.config.mappingMode
+		m.logger = mc.config.logger
+	}
+	return m
+}
+
+// checkMapping applies the mapping mode to columns without a field and tagged fields without a column.
+func (m *mapper) checkMapping(structT reflect.Type, plan []columnPlan, fields map[string]fieldInfo) error {
+	if m.mode == MappingIgnore {
+		return nil
+	}
+	var columns []string
+	matched := make(map[string]bool, len(plan))
+	for _, p := range plan {
+		if p.index == nil {
+			columns = append(columns, p.column.name)
+			continue
+		}
+		info, _ := lookupField(fields, p.column.name)
+		matched[info.name] = true
+	}
+	var missing []string
+	for name, info := range fields {
+		if info.tagged && !matched[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(columns) == 0 && len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	err := &MappingError{Type: structT, Columns: columns, Fields: missing}
+	if m.mode == MappingStrict {
+		return err
+	}
+	m.getLogger().Printf("%v", err)
+	return nil
+}
+
+func (m *mapper) getLogger() Logger {
+	if m.logger == nil {
+		return log.Default()
+	}
+	return m.logger
+}
+
+// columnPlan is how one result column is decoded, resolved once per result set.
+// A nil index means the column has no matching field.
+type columnPlan struct {
+	column columnInfo
+	index  []int
+	decode DecodeFunc
+}
+
+type rowScanner struct {
+	plan    []columnPlan
+	cols    []interface{}
+	colPtrs []interface{}
+}
+
+func newRowScanner(rows *sql.Rows, structT reflect.Type, m *mapper) (*rowScanner, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	fields := cachedStructFields(structT)
+	rs := &rowScanner{
+		plan:    make([]columnPlan, len(columnTypes)),
+		cols:    make([]interface{}, len(columnTypes)),
+		colPtrs: make([]interface{}, len(columnTypes)),
+	}
+	for i, columnType := range columnTypes {
+		rs.colPtrs[i] = &rs.cols[i]
+		rs.plan[i] = newColumnPlan(newColumnInfo(columnType), structT, fields, m.converters)
+	}
+	err = m.checkMapping(structT, rs.plan, fields)
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func newColumnPlan(column columnInfo, structT reflect.Type, fields map[string]fieldInfo, converters *converterRegistry) columnPlan {
+	plan := columnPlan{column: column}
+	info, ok := lookupField(fields, column.name)
+	if !ok {
+		return plan
+	}
+	plan.index = info.index
+	if info.json {
+		plan.decode = nullable(decodeJSON)
+	} else {
+		plan.decode = converters.decoder(structT.FieldByIndex(info.index).Type, column)
+	}
+	return plan
+}
+
+// scan decodes the current row into dest, which must be an addressable struct value.
+func (rs *rowScanner) scan(rows *sql.Rows, dest reflect.Value) error {
+	err := rows.Scan(rs.colPtrs...)
+	if err != nil {
+		return err
+	}
+	return rs.assign(dest)
+}
+
+func (rs *rowScanner) assign(dest reflect.Value) error {
+	for i, col := range rs.cols {
+		plan := &rs.plan[i]
+		if plan.index == nil {
+			continue
+		}
+		field, ok := fieldByIndex(dest, plan.index, col != nil)
+		if !ok {
+			continue
+		}
+		err := plan.decode(field, col)
+		if err != nil {
+			return fmt.Errorf("column %s: %v", plan.column.name, err)
+		}
+	}
+	return nil
+}
+
+func isMappableStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+func scanRows(rows *sql.Rows, input interface{}, m *mapper) error {
+	sliceV := reflect.ValueOf(input).Elem()
+	elemT := sliceV.Type().Elem()
+	structT := elemT
+	if elemT.Kind() == reflect.Ptr {
+		structT = elemT.Elem()
+	}
+	if !isMappableStruct(structT) {
+		return fmt.Errorf("%v must be a slice of struct or struct pointer", sliceV.Type())
+	}
+	rs, err := newRowScanner(rows, structT, m)
+	if err != nil {
+		return err
+	}
+	result := reflect.MakeSlice(sliceV.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(structT)
+		err = rs.scan(rows, elem.Elem())
+		if err != nil {
+			return err
+		}
+		if elemT.Kind() == reflect.Ptr {
+			result = reflect.Append(result, elem)
+		} else {
+			result = reflect.Append(result, elem.Elem())
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	sliceV.Set(result)
+	return nil
+}
+
+// scanFirst decodes the first row into input, which must be a struct pointer.
+// With single set, a second row is reported as TooManyRowsError.
+func scanFirst(rows *sql.Rows, input interface{}, single bool, m *mapper) error {
+	structV := reflect.ValueOf(input).Elem()
+	rs, err := newRowScanner(rows, structV.Type(), m)
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		err = rows.Err()
+		if err != nil {
+			return err
+		}
+		return RecordNotFoundError
+	}
+	elem := reflect.New(structV.Type())
+	err = rs.scan(rows, elem.Elem())
+	if err != nil {
+		return err
+	}
+	if single && rows.Next() {
+		return TooManyRowsError
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	structV.Set(elem.Elem())
+	return nil
+}
@@ -0,0 +1,237 @@
+package mysqlclient
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type mapperUser struct {
+	Id          int64
+	UserName    string `db:"name"`
+	Age         int    `mysql:"user_age"`
+	IsDelete    bool
+	Ignored     string `db:"-"`
+	CreatedTime time.Time
+	password    string
+}
+
+func TestToSnakeCase(t *testing.T) {
+	assert.EqualValues(t, "id", toSnakeCase("Id"))
+	assert.EqualValues(t, "id", toSnakeCase("ID"))
+	assert.EqualValues(t, "user_id", toSnakeCase("UserID"))
+	assert.EqualValues(t, "created_time", toSnakeCase("CreatedTime"))
+	assert.EqualValues(t, "http_server", toSnakeCase("HTTPServer"))
+	assert.EqualValues(t, "address2", toSnakeCase("Address2"))
+}
+
+func TestStructFields(t *testing.T) {
+	fields := structFields(reflect.TypeOf(mapperUser{}))
+	assert.EqualValues(t, map[string]fieldInfo{
+		"id":           {name: "id", column: "id", index: []int{0}},
+		"name":         {name: "name", column: "name", index: []int{1}, tagged: true},
+		"user_age":     {name: "user_age", column: "user_age", index: []int{2}, tagged: true},
+		"is_delete":    {name: "is_delete", column: "is_delete", index: []int{3}},
+		"created_time": {name: "created_time", column: "created_time", index: []int{5}},
+	}, fields)
+}
+
+type mapperOrder struct {
+	Id     int64
+	Amount int64
+}
+
+type mapperBase struct {
+	Id int64
+}
+
+type mapperUserOrder struct {
+	mapperBase
+	User  mapperUser   `db:"u"`
+	Order *mapperOrder `db:"o"`
+}
+
+func TestStructFieldsNested(t *testing.T) {
+	fields := structFields(reflect.TypeOf(mapperUserOrder{}))
+	assert.EqualValues(t, []int{0, 0}, fields["id"].index)
+	assert.EqualValues(t, []int{1, 0}, fields["u.id"].index)
+	assert.EqualValues(t, []int{1, 1}, fields["u.name"].index)
+	assert.EqualValues(t, []int{2, 0}, fields["o.id"].index)
+	assert.EqualValues(t, []int{2, 1}, fields["o.amount"].index)
+
+	field, ok := lookupField(fields, "O.Amount")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{2, 1}, field.index)
+	field, ok = lookupField(fields, "x.id")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{0, 0}, field.index)
+}
+
+func TestFieldByIndexLeavesNilForNull(t *testing.T) {
+	var result mapperUserOrder
+	v := reflect.ValueOf(&result).Elem()
+	_, ok := fieldByIndex(v, []int{2, 0}, false)
+	assert.False(t, ok)
+	assert.Nil(t, result.Order)
+
+	field, ok := fieldByIndex(v, []int{2, 1}, true)
+	assert.True(t, ok)
+	field.SetInt(100)
+	assert.EqualValues(t, 100, result.Order.Amount)
+}
+
+type mapperHidden struct {
+	Id int64
+}
+
+type mapperEmbedsUnexported struct {
+	*mapperHidden
+	Name string
+}
+
+func TestStructFieldsSkipsUnexportedEmbeddedPointer(t *testing.T) {
+	userT := reflect.TypeOf(mapperEmbedsUnexported{})
+	fields := structFields(userT)
+	_, ok := fields["id"]
+	assert.False(t, ok)
+	rs := &rowScanner{
+		plan: []columnPlan{
+			newColumnPlan(columnInfo{name: "id", typeName: "BIGINT"}, userT, fields, defaultConverters),
+			newColumnPlan(columnInfo{name: "name", typeName: "VARCHAR"}, userT, fields, defaultConverters),
+		},
+		cols: []interface{}{int64(1), []byte("test name")},
+	}
+	var result mapperEmbedsUnexported
+	assert.Nil(t, rs.assign(reflect.ValueOf(&result).Elem()))
+	assert.Nil(t, result.mapperHidden)
+	assert.EqualValues(t, "test name", result.Name)
+}
+
+type mapperEvent struct {
+	Id      int64
+	Payload map[string]interface{}
+	Detail  mapperOrder `db:"detail,json"`
+}
+
+func TestStructFieldsJSON(t *testing.T) {
+	fields := structFields(reflect.TypeOf(mapperEvent{}))
+	assert.EqualValues(t, fieldInfo{name: "payload", column: "payload", index: []int{1}}, fields["payload"])
+	assert.EqualValues(t, fieldInfo{name: "detail", column: "detail", index: []int{2}, tagged: true, json: true}, fields["detail"])
+	_, ok := fields["detail.id"]
+	assert.False(t, ok)
+}
+
+func TestCachedStructFields(t *testing.T) {
+	userT := reflect.TypeOf(mapperUser{})
+	done := make(chan map[string]fieldInfo)
+	for i := 0; i < 8; i++ {
+		go func() {
+			done <- cachedStructFields(userT)
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		assert.EqualValues(t, structFields(userT), <-done)
+	}
+	first := reflect.ValueOf(cachedStructFields(userT)).Pointer()
+	assert.EqualValues(t, first, reflect.ValueOf(cachedStructFields(userT)).Pointer())
+}
+
+func newBenchmarkScanner() (*rowScanner, reflect.Type) {
+	userT := reflect.TypeOf(mapperUser{})
+	columns := []columnInfo{
+		{name: "id", typeName: "BIGINT"},
+		{name: "name", typeName: "VARCHAR"},
+		{name: "user_age", typeName: "INT"},
+		{name: "is_delete", typeName: "BIT"},
+		{name: "created_time", typeName: "TIMESTAMP"},
+	}
+	rs := &rowScanner{
+		plan: make([]columnPlan, len(columns)),
+		cols: []interface{}{int64(1), []byte("test name"), int64(21), []byte{0}, time.Now()},
+	}
+	for i, column := range columns {
+		rs.plan[i] = newColumnPlan(column, userT, cachedStructFields(userT), defaultConverters)
+	}
+	return rs, userT
+}
+
+func TestRowScannerAssign(t *testing.T) {
+	rs, userT := newBenchmarkScanner()
+	user := reflect.New(userT)
+	assert.Nil(t, rs.assign(user.Elem()))
+	result := user.Interface().(*mapperUser)
+	assert.EqualValues(t, 1, result.Id)
+	assert.EqualValues(t, "test name", result.UserName)
+	assert.EqualValues(t, 21, result.Age)
+	assert.False(t, result.IsDelete)
+}
+
+// BenchmarkRowScannerAssign and BenchmarkHandWrittenAssign compare the mapper against
+// assigning the same driver values by hand, without the database round trip.
+func BenchmarkRowScannerAssign(b *testing.B) {
+	rs, userT := newBenchmarkScanner()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		user := reflect.New(userT)
+		err := rs.assign(user.Elem())
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHandWrittenAssign(b *testing.B) {
+	rs, _ := newBenchmarkScanner()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var user mapperUser
+		user.Id = rs.cols[0].(int64)
+		user.UserName = string(rs.cols[1].([]byte))
+		user.Age = int(rs.cols[2].(int64))
+		user.IsDelete = rs.cols[3].([]byte)[0] == 1
+		user.CreatedTime = rs.cols[4].(time.Time)
+	}
+}
+
+type recordLogger struct {
+	messages []string
+}
+
+func (l *recordLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestCheckMapping(t *testing.T) {
+	userT := reflect.TypeOf(mapperUser{})
+	fields := cachedStructFields(userT)
+	var plan []columnPlan
+	for _, name := range []string{"id", "name", "password"} {
+		plan = append(plan, newColumnPlan(columnInfo{name: name, typeName: "VARCHAR"}, userT, fields, defaultConverters))
+	}
+
+	m := &mapper{converters: defaultConverters, mode: MappingIgnore}
+	assert.Nil(t, m.checkMapping(userT, plan, fields))
+
+	logger := &recordLogger{}
+	m = &mapper{converters: defaultConverters, mode: MappingWarn, logger: logger}
+	assert.Nil(t, m.checkMapping(userT, plan, fields))
+	assert.EqualValues(t, 1, len(logger.messages))
+
+	m = &mapper{converters: defaultConverters, mode: MappingStrict}
+	err := m.checkMapping(userT, plan, fields)
+	var mappingError *MappingError
+	assert.True(t, errors.As(err, &mappingError))
+	assert.EqualValues(t, []string{"password"}, mappingError.Columns)
+	assert.EqualValues(t, []string{"user_age"}, mappingError.Fields)
+}
+
+func TestWithMappingMode(t *testing.T) {
+	mc := &MysqlClient{config: &Config{mappingMode: MappingIgnore}, converters: newConverterRegistry()}
+	strict := mc.WithMappingMode(MappingStrict)
+	assert.EqualValues(t, MappingStrict, strict.mapper().mode)
+	assert.EqualValues(t, MappingIgnore, mc.mapper().mode)
+	assert.True(t, strict.converters == mc.converters)
+}
@@ -0,0 +1,115 @@
+package mysqlclient
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// namedParam is one :name placeholder, spanning query[start:end].
+type namedParam struct {
+	name  string
+	start int
+	end   int
+}
+
+// namedParams finds the :name placeholders of query. Names may contain dots to reach nested struct fields.
+// String literals, quoted identifiers, comments, :: casts and := assignments are left alone.
+func namedParams(query string) []namedParam {
+	var params []namedParam
+	walkSQL(query, func(i int) int {
+		if query[i] != ':' {
+			return i
+		}
+		if i+1 < len(query) && query[i+1] == ':' {
+			// skip both colons of a cast
+			return i + 1
+		}
+		if i+1 >= len(query) || !isNameStart(query[i+1]) {
+			return i
+		}
+		end := i + 2
+		for end < len(query) && (isNameStart(query[end]) || (query[end] >= '0' && query[end] <= '9') || query[end] == '.') {
+			end++
+		}
+		params = append(params, namedParam{name: query[i+1 : end], start: i, end: end})
+		return end - 1
+	})
+	return params
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// bindNamed rewrites the :name placeholders of query to ? and returns their values in order,
+// read from source: a map[string]interface{} or a struct (pointer) mapped by its db tags.
+// A query without named placeholders is returned unchanged with ok false.
+func bindNamed(query string, source interface{}) (string, []interface{}, bool, error) {
+	var lookup func(name string) (interface{}, bool, error)
+	switch values := source.(type) {
+	case map[string]interface{}:
+		lookup = func(name string) (interface{}, bool, error) {
+			value, ok := values[name]
+			return value, ok, nil
+		}
+	default:
+		structV := reflect.ValueOf(source)
+		if structV.Kind() == reflect.Ptr {
+			if structV.IsNil() {
+				return query, nil, false, nil
+			}
+			structV = structV.Elem()
+		}
+		if !structV.IsValid() || !isMappableStruct(structV.Type()) {
+			return query, nil, false, nil
+		}
+		fields := cachedStructFields(structV.Type())
+		lookup = func(name string) (interface{}, bool, error) {
+			info, ok := fields[strings.ToLower(name)]
+			if !ok {
+				return nil, false, nil
+			}
+			value, err := fieldValue(structV, info)
+			return value, true, err
+		}
+	}
+	params := namedParams(query)
+	if len(params) == 0 {
+		return query, nil, false, nil
+	}
+	var sb strings.Builder
+	args := make([]interface{}, len(params))
+	last := 0
+	for i, param := range params {
+		value, ok, err := lookup(param.name)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if !ok {
+			return "", nil, false, fmt.Errorf("no value for named parameter :%s", param.name)
+		}
+		sb.WriteString(query[last:param.start])
+		sb.WriteString("?")
+		last = param.end
+		args[i] = value
+	}
+	sb.WriteString(query[last:])
+	return sb.String(), args, true, nil
+}
+
+// resolveNamed binds the :name placeholders of query when args is a single map or struct,
+// so that positional arguments can be appended to the result.
+func resolveNamed(query string, args []interface{}) (string, []interface{}, error) {
+	if len(args) != 1 {
+		return query, args, nil
+	}
+	named, namedArgs, ok, err := bindNamed(query, args[0])
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		return query, args, nil
+	}
+	return named, namedArgs, nil
+}
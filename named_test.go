@@ -0,0 +1,47 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNamedParams(t *testing.T) {
+	params := namedParams("update userinfo set name = :name, age=:age where id = :id")
+	assert.EqualValues(t, []namedParam{{"name", 27, 32}, {"age", 38, 42}, {"id", 54, 57}}, params)
+	assert.Nil(t, namedParams("select ':name', `:name`, \":name\" -- :name\n/* :name */ from t"))
+	assert.Nil(t, namedParams("select @a := 1, '12:30:00', x::text, ? from t"))
+	assert.EqualValues(t, []namedParam{{"user.id", 14, 22}}, namedParams("select 1 where:user.id"))
+}
+
+func TestBindNamed(t *testing.T) {
+	query, args, ok, err := bindNamed("update userinfo set name = :name where id = :id and name <> :name", map[string]interface{}{"id": 1, "name": "test name"})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, "update userinfo set name = ? where id = ? and name <> ?", query)
+	assert.EqualValues(t, []interface{}{"test name", 1, "test name"}, args)
+
+	user := &crudUser{Id: 3, Name: "test name", Tags: []string{"a"}}
+	query, args, ok, err = bindNamed("update userinfo set tags = :tags, name = :Name where id = :id", user)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, "update userinfo set tags = ?, name = ? where id = ?", query)
+	assert.EqualValues(t, []interface{}{`["a"]`, "test name", int64(3)}, args)
+
+	_, _, _, err = bindNamed("select * from userinfo where id = :missing", user)
+	assert.NotNil(t, err)
+
+	_, _, ok, err = bindNamed("select * from userinfo where id = ?", user)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, ok, err = bindNamed("select * from userinfo where id = :id", 3)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestBind_Named(t *testing.T) {
+	query, args, err := defaultConverters.bind("delete from userinfo where id in (:ids) and name = :name", []interface{}{map[string]interface{}{"ids": []int{1, 2}, "name": "test name"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "delete from userinfo where id in (?, ?) and name = ?", query)
+	assert.EqualValues(t, []interface{}{1, 2, "test name"}, args)
+}
@@ -3,9 +3,34 @@ package mysqlclient
 import "database/sql"
 
 type Config struct {
-	pool    *sql.DB
-	ddlPath string
-	flyway  bool
+	pool        *sql.DB
+	ddlPath     string
+	flyway      bool
+	mappingMode MappingMode
+	logger      Logger
+	// maxAllowedPacket and batchSize bound each multi-row statement built by BatchInsert;
+	// a zero maxAllowedPacket is read from the server at startup
+	maxAllowedPacket int64
+	batchSize        int
+	// stmtCacheSize is the most prepared statements Insert, Update and Delete keep open
+	stmtCacheSize int
+	// inListSize is the most elements of a slice argument Exec binds in one statement
+	inListSize int
+}
+
+// MappingMode decides what happens when a result column has no struct field,
+// or a tagged struct field has no result column.
+type MappingMode int
+
+const (
+	MappingIgnore MappingMode = iota
+	MappingWarn
+	MappingStrict
+)
+
+// Logger is satisfied by *log.Logger and most structured loggers.
+type Logger interface {
+	Printf(format string, v ...interface{})
 }
 
 type Option func(*Config)
@@ -27,3 +52,48 @@ func Flyway(flyway bool) Option {
 		c.flyway = flyway
 	}
 }
+
+// Mapping sets the default MappingMode; MappingIgnore keeps select * queries working,
+// MappingStrict returns a *MappingError and suits CI.
+func Mapping(mappingMode MappingMode) Option {
+	return func(c *Config) {
+		c.mappingMode = mappingMode
+	}
+}
+
+func Log(logger Logger) Option {
+	return func(c *Config) {
+		c.logger = logger
+	}
+}
+
+// MaxAllowedPacket overrides the server's max_allowed_packet, which is otherwise read at startup;
+// BatchInsert splits its statements to stay below it. Set it when dbclient.MaxAllowedPacket caps the driver lower.
+func MaxAllowedPacket(maxAllowedPacket int64) Option {
+	return func(c *Config) {
+		c.maxAllowedPacket = maxAllowedPacket
+	}
+}
+
+// BatchSize is the most rows BatchInsert puts into one statement (default 1000).
+func BatchSize(batchSize int) Option {
+	return func(c *Config) {
+		c.batchSize = batchSize
+	}
+}
+
+// StmtCacheSize is the most prepared statements kept open for Insert, Update and Delete (default 100, 0 disables).
+// It is further capped by the server's max_prepared_stmt_count.
+func StmtCacheSize(stmtCacheSize int) Option {
+	return func(c *Config) {
+		c.stmtCacheSize = stmtCacheSize
+	}
+}
+
+// InListSize is the most elements of a slice argument bound by one Update or Delete (default 1000).
+// Longer lists run the statement once per chunk inside a transaction.
+func InListSize(inListSize int) Option {
+	return func(c *Config) {
+		c.inListSize = inListSize
+	}
+}
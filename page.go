@@ -0,0 +1,240 @@
+package mysqlclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Page struct {
+	Page       int
+	Size       int
+	Total      int64
+	TotalPages int
+	HasNext    bool
+}
+
+// KeysetKey is one column of a keyset ordering. Column must name a column of the query's result.
+type KeysetKey struct {
+	Column string
+	Desc   bool
+}
+
+// FindPage decodes page (starting at 1) of size rows into input like Find and returns the page metadata.
+// The total comes from running sql as a derived table under COUNT(1).
+func (mc *MysqlClient) FindPage(sql string, page int, size int, input interface{}, args ...interface{}) (*Page, error) {
+	return mc.FindPageContext(context.Background(), sql, page, size, input, args...)
+}
+
+func (mc *MysqlClient) FindPageContext(ctx context.Context, sql string, page int, size int, input interface{}, args ...interface{}) (*Page, error) {
+	if !isSlicePtr(input) {
+		return nil, fmt.Errorf("%v must be a slice pointer", input)
+	}
+	if page < 1 || size < 1 {
+		return nil, fmt.Errorf("invalid page %d or size %d", page, size)
+	}
+	// bind :name placeholders before the offset and size are appended
+	sql, args, err := resolveNamed(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	// filter soft-deleted rows once, before counting and limiting
+	sql, err = mc.scoped(sql, reflect.TypeOf(input).Elem().Elem())
+	if err != nil {
+		return nil, err
+	}
+	mc = mc.Unscoped()
+	total, err := mc.CountContext(ctx, countSQL(sql), args...)
+	if err != nil {
+		return nil, err
+	}
+	result := &Page{
+		Page:       page,
+		Size:       size,
+		Total:      total,
+		TotalPages: int((total + int64(size) - 1) / int64(size)),
+	}
+	result.HasNext = page < result.TotalPages
+	offset := int64(page-1) * int64(size)
+	if offset >= total {
+		sliceV := reflect.ValueOf(input).Elem()
+		sliceV.Set(reflect.MakeSlice(sliceV.Type(), 0, 0))
+		return result, nil
+	}
+	pageArgs := append(append([]interface{}(nil), args...), offset, size)
+	err = mc.FindContext(ctx, trimSQL(sql)+" LIMIT ?, ?", input, pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindKeyset decodes up to size rows following cursor into input, ordered by keys.
+// An empty cursor starts from the beginning; the returned cursor is empty once there are no more rows.
+// sql must not have its own ORDER BY or LIMIT, and key columns must not be NULL.
+func (mc *MysqlClient) FindKeyset(sql string, keys []KeysetKey, cursor string, size int, input interface{}, args ...interface{}) (string, error) {
+	return mc.FindKeysetContext(context.Background(), sql, keys, cursor, size, input, args...)
+}
+
+func (mc *MysqlClient) FindKeysetContext(ctx context.Context, sql string, keys []KeysetKey, cursor string, size int, input interface{}, args ...interface{}) (string, error) {
+	if !isSlicePtr(input) {
+		return "", fmt.Errorf("%v must be a slice pointer", input)
+	}
+	if len(keys) == 0 || size < 1 {
+		return "", fmt.Errorf("keyset pagination needs at least one key and a positive size")
+	}
+	var values []interface{}
+	if cursor != "" {
+		var err error
+		values, err = decodeCursor(cursor)
+		if err != nil {
+			return "", err
+		}
+		if len(values) != len(keys) {
+			return "", fmt.Errorf("cursor has %d values for %d keys", len(values), len(keys))
+		}
+	}
+	sql, args, err := resolveNamed(sql, args)
+	if err != nil {
+		return "", err
+	}
+	sql, err = mc.scoped(sql, reflect.TypeOf(input).Elem().Elem())
+	if err != nil {
+		return "", err
+	}
+	query, keysetArgs := keysetSQL(sql, keys, values)
+	pageArgs := append(append(append([]interface{}(nil), args...), keysetArgs...), size+1)
+	err = mc.Unscoped().FindContext(ctx, query, input, pageArgs...)
+	if err != nil {
+		return "", err
+	}
+	sliceV := reflect.ValueOf(input).Elem()
+	if sliceV.Len() <= size {
+		return "", nil
+	}
+	sliceV.Set(sliceV.Slice(0, size))
+	return nextCursor(sliceV.Index(size-1), keys)
+}
+
+func trimSQL(sql string) string {
+	return strings.TrimRight(strings.TrimSpace(sql), "; \t\n")
+}
+
+func countSQL(sql string) string {
+	return fmt.Sprintf("SELECT COUNT(1) FROM (%s) AS count_page", trimSQL(sql))
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// keysetSQL wraps sql in a derived table and seeks past values, expanding the row comparison
+// into (k1 > ?) OR (k1 = ? AND k2 > ?) ... so that keys may mix ascending and descending order.
+func keysetSQL(sql string, keys []KeysetKey, values []interface{}) (string, []interface{}) {
+	var sb strings.Builder
+	var args []interface{}
+	sb.WriteString("SELECT * FROM (")
+	sb.WriteString(trimSQL(sql))
+	sb.WriteString(") AS keyset_page")
+	if len(values) > 0 {
+		var or []string
+		for i, key := range keys {
+			var and []string
+			for j := 0; j < i; j++ {
+				and = append(and, quoteIdentifier(keys[j].Column)+" = ?")
+				args = append(args, values[j])
+			}
+			op := " > ?"
+			if key.Desc {
+				op = " < ?"
+			}
+			and = append(and, quoteIdentifier(key.Column)+op)
+			args = append(args, values[i])
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(or, " OR "))
+	}
+	var order []string
+	for _, key := range keys {
+		if key.Desc {
+			order = append(order, quoteIdentifier(key.Column)+" DESC")
+		} else {
+			order = append(order, quoteIdentifier(key.Column))
+		}
+	}
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(strings.Join(order, ", "))
+	sb.WriteString(" LIMIT ?")
+	return sb.String(), args
+}
+
+func nextCursor(elem reflect.Value, keys []KeysetKey) (string, error) {
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	fields := cachedStructFields(elem.Type())
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		info, ok := lookupField(fields, key.Column)
+		if !ok {
+			return "", fmt.Errorf("keyset column %s has no field in %v", key.Column, elem.Type())
+		}
+		field, ok := fieldByIndex(elem, info.index, false)
+		if !ok {
+			return "", fmt.Errorf("keyset column %s is NULL", key.Column)
+		}
+		for field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return "", fmt.Errorf("keyset column %s is NULL", key.Column)
+			}
+			field = field.Elem()
+		}
+		value := field.Interface()
+		if t, ok := value.(time.Time); ok {
+			value = t.Format("2006-01-02 15:04:05.999999")
+		}
+		values[i] = value
+	}
+	return encodeCursor(values)
+}
+
+// encodeCursor makes the opaque token handed to clients: base64url encoded JSON of the key values.
+func encodeCursor(values []interface{}) (string, error) {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(b)))
+	decoder.UseNumber()
+	var values []interface{}
+	err = decoder.Decode(&values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+	for i, value := range values {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		// keep integers exact; anything else is passed as a string and converted by MySQL
+		if n, err := number.Int64(); err == nil {
+			values[i] = n
+		} else {
+			values[i] = number.String()
+		}
+	}
+	return values, nil
+}
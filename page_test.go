@@ -0,0 +1,68 @@
+package mysqlclient
+
+import (
+	"github.com/sillyhatxu/mysql-client/example/model"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCountSQL(t *testing.T) {
+	assert.EqualValues(t, "SELECT COUNT(1) FROM (select * from user where age > ?) AS count_page", countSQL(" select * from user where age > ?;\n"))
+}
+
+func TestKeysetSQL(t *testing.T) {
+	keys := []KeysetKey{{Column: "created_time", Desc: true}, {Column: "id"}}
+	query, args := keysetSQL("select * from user", keys, nil)
+	assert.EqualValues(t, "SELECT * FROM (select * from user) AS keyset_page ORDER BY `created_time` DESC, `id` LIMIT ?", query)
+	assert.Nil(t, args)
+
+	query, args = keysetSQL("select * from user", keys, []interface{}{"2019-02-27 05:39:55", int64(2)})
+	assert.EqualValues(t, "SELECT * FROM (select * from user) AS keyset_page WHERE (`created_time` < ?) OR (`created_time` = ? AND `id` > ?) ORDER BY `created_time` DESC, `id` LIMIT ?", query)
+	assert.EqualValues(t, []interface{}{"2019-02-27 05:39:55", "2019-02-27 05:39:55", int64(2)}, args)
+}
+
+func TestFindKeyset_Named(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	var users []mapperUser
+	_, err := mc.FindKeyset("select * from user where age > :age", []KeysetKey{{Column: "id"}}, "", 10, &users, map[string]interface{}{"age": 20})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []string{"SELECT * FROM (select * from user where age > ?) AS keyset_page ORDER BY `id` LIMIT ?"}, d.queries)
+}
+
+func TestKeysetCursor(t *testing.T) {
+	created := time.Date(2019, 2, 27, 5, 39, 55, 0, time.UTC)
+	user := &mapperUser{Id: 9007199254740993, UserName: "test name", CreatedTime: created}
+	cursor, err := nextCursor(reflect.ValueOf(user), []KeysetKey{{Column: "created_time"}, {Column: "id"}, {Column: "name"}})
+	assert.Nil(t, err)
+	values, err := decodeCursor(cursor)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []interface{}{"2019-02-27 05:39:55", int64(9007199254740993), "test name"}, values)
+
+	_, err = nextCursor(reflect.ValueOf(user), []KeysetKey{{Column: "unknown"}})
+	assert.NotNil(t, err)
+	_, err = decodeCursor("not a cursor")
+	assert.NotNil(t, err)
+}
+
+func TestMysqlClient_FindPage(t *testing.T) {
+	once.Do(setup)
+	var userArray []model.User
+	page, err := mysqlClient.FindPage("select * from user", 1, 10, &userArray)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, page.Total)
+	assert.EqualValues(t, 1, page.TotalPages)
+	assert.False(t, page.HasNext)
+	assert.EqualValues(t, 1, len(userArray))
+}
+
+func TestMysqlClient_FindKeyset(t *testing.T) {
+	once.Do(setup)
+	var userArray []model.User
+	cursor, err := mysqlClient.FindKeyset("select * from user", []KeysetKey{{Column: "id"}}, "", 10, &userArray)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", cursor)
+	assert.EqualValues(t, 1, len(userArray))
+}
@@ -0,0 +1,235 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// queryer is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Query decodes every row into T, which is either a struct (or struct pointer) mapped like Find,
+// or a scalar type read from a single column. Soft-deleted rows of a struct T are left out like Find.
+func Query[T any](mc *MysqlClient, sql string, args ...interface{}) ([]T, error) {
+	return QueryContext[T](context.Background(), mc, sql, args...)
+}
+
+func QueryContext[T any](ctx context.Context, mc *MysqlClient, sql string, args ...interface{}) ([]T, error) {
+	sql, args, err := bindScoped[T](mc, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return query[T](ctx, mc.session(ctx), mc.mapper(), sql, args...)
+}
+
+// QueryOne decodes the only row into T. It returns RecordNotFoundError for no rows and TooManyRowsError for more than one.
+func QueryOne[T any](mc *MysqlClient, sql string, args ...interface{}) (T, error) {
+	return QueryOneContext[T](context.Background(), mc, sql, args...)
+}
+
+func QueryOneContext[T any](ctx context.Context, mc *MysqlClient, sql string, args ...interface{}) (T, error) {
+	sql, args, err := bindScoped[T](mc, sql, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return queryOne[T](ctx, mc.session(ctx), mc.mapper(), sql, args...)
+}
+
+// QueryScalar reads the first column of the first row, e.g. COUNT(1), MAX(id), SUM(amount) or EXISTS(...).
+// A NULL result leaves T at its zero value; use a pointer type to tell NULL apart.
+func QueryScalar[T any](mc *MysqlClient, sql string, args ...interface{}) (T, error) {
+	return QueryScalarContext[T](context.Background(), mc, sql, args...)
+}
+
+func QueryScalarContext[T any](ctx context.Context, mc *MysqlClient, sql string, args ...interface{}) (T, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return queryScalar[T](ctx, mc.session(ctx), mc.mapper(), sql, args...)
+}
+
+// QueryTx, QueryOneTx and QueryScalarTx run inside tx with mc's converters and mapping mode.
+func QueryTx[T any](mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) ([]T, error) {
+	return QueryTxContext[T](context.Background(), mc, tx, sql, args...)
+}
+
+func QueryTxContext[T any](ctx context.Context, mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) ([]T, error) {
+	sql, args, err := bindScoped[T](mc, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return query[T](ctx, tx, mc.mapper(), sql, args...)
+}
+
+func QueryOneTx[T any](mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) (T, error) {
+	return QueryOneTxContext[T](context.Background(), mc, tx, sql, args...)
+}
+
+func QueryOneTxContext[T any](ctx context.Context, mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) (T, error) {
+	sql, args, err := bindScoped[T](mc, sql, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return queryOne[T](ctx, tx, mc.mapper(), sql, args...)
+}
+
+func QueryScalarTx[T any](mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) (T, error) {
+	return QueryScalarTxContext[T](context.Background(), mc, tx, sql, args...)
+}
+
+func QueryScalarTxContext[T any](ctx context.Context, mc *MysqlClient, tx *sql.Tx, sql string, args ...interface{}) (T, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return queryScalar[T](ctx, tx, mc.mapper(), sql, args...)
+}
+
+// bindScoped binds args into sql and filters soft-deleted rows of a struct T.
+func bindScoped[T any](mc *MysqlClient, sql string, args []interface{}) (string, []interface{}, error) {
+	sql, args, err := mc.getConverters().bind(sql, args)
+	if err != nil {
+		return "", nil, err
+	}
+	sql, err = mc.scoped(sql, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, args, nil
+}
+
+func query[T any](ctx context.Context, q queryer, m *mapper, sql string, args ...interface{}) ([]T, error) {
+	rows, err := q.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := make([]T, 0)
+	if !isScalarType(reflect.TypeOf(results).Elem()) {
+		err = scanRows(rows, &results, m)
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	column, err := scalarColumn(rows)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var result T
+		err = scanScalar(rows, column, reflect.ValueOf(&result).Elem(), m)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func queryOne[T any](ctx context.Context, q queryer, m *mapper, sql string, args ...interface{}) (T, error) {
+	var result T
+	resultT := reflect.TypeOf(&result).Elem()
+	if isScalarType(resultT) {
+		return scalar[T](ctx, q, m, true, sql, args...)
+	}
+	rows, err := q.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	if resultT.Kind() == reflect.Ptr {
+		elem := reflect.New(resultT.Elem())
+		err = scanFirst(rows, elem.Interface(), true, m)
+		if err != nil {
+			return result, err
+		}
+		reflect.ValueOf(&result).Elem().Set(elem)
+		return result, nil
+	}
+	err = scanFirst(rows, &result, true, m)
+	return result, err
+}
+
+func queryScalar[T any](ctx context.Context, q queryer, m *mapper, sql string, args ...interface{}) (T, error) {
+	var result T
+	if !isScalarType(reflect.TypeOf(&result).Elem()) {
+		return result, fmt.Errorf("%v is not a scalar type", reflect.TypeOf(&result).Elem())
+	}
+	return scalar[T](ctx, q, m, false, sql, args...)
+}
+
+func scalar[T any](ctx context.Context, q queryer, m *mapper, single bool, sql string, args ...interface{}) (T, error) {
+	var result T
+	rows, err := q.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	column, err := scalarColumn(rows)
+	if err != nil {
+		return result, err
+	}
+	if !rows.Next() {
+		err = rows.Err()
+		if err != nil {
+			return result, err
+		}
+		return result, RecordNotFoundError
+	}
+	var value T
+	err = scanScalar(rows, column, reflect.ValueOf(&value).Elem(), m)
+	if err != nil {
+		return result, err
+	}
+	if single && rows.Next() {
+		return result, TooManyRowsError
+	}
+	err = rows.Err()
+	if err != nil {
+		return result, err
+	}
+	return value, nil
+}
+
+func scalarColumn(rows *sql.Rows) (columnInfo, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return columnInfo{}, err
+	}
+	if len(columnTypes) != 1 {
+		return columnInfo{}, fmt.Errorf("scalar query must return exactly one column, got %d", len(columnTypes))
+	}
+	return newColumnInfo(columnTypes[0]), nil
+}
+
+func scanScalar(rows *sql.Rows, column columnInfo, dest reflect.Value, m *mapper) error {
+	var col interface{}
+	err := rows.Scan(&col)
+	if err != nil {
+		return err
+	}
+	return m.converters.decode(dest, column, col)
+}
+
+// isScalarType reports whether t is read from a single column rather than mapped field by field.
+// time.Time and types implementing sql.Scanner count as scalars.
+func isScalarType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return !isMappableStruct(t)
+}
@@ -0,0 +1,52 @@
+package mysqlclient
+
+import (
+	"database/sql"
+	"github.com/sillyhatxu/mysql-client/example/model"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsScalarType(t *testing.T) {
+	assert.True(t, isScalarType(reflect.TypeOf(int64(0))))
+	assert.True(t, isScalarType(reflect.TypeOf("")))
+	assert.True(t, isScalarType(reflect.TypeOf(time.Time{})))
+	assert.True(t, isScalarType(reflect.TypeOf(&time.Time{})))
+	assert.True(t, isScalarType(reflect.TypeOf(sql.NullInt64{})))
+	assert.True(t, isScalarType(reflect.TypeOf(&sql.NullString{})))
+	assert.False(t, isScalarType(reflect.TypeOf(model.User{})))
+	assert.False(t, isScalarType(reflect.TypeOf(&model.User{})))
+}
+
+func TestMysqlClient_QueryScalar(t *testing.T) {
+	once.Do(setup)
+	count, err := QueryScalar[int64](mysqlClient, "select count(1) from user")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+	exist, err := QueryScalar[bool](mysqlClient, "select exists(select 1 from user where id = ?)", -1)
+	assert.Nil(t, err)
+	assert.False(t, exist)
+	users, err := Query[model.User](mysqlClient, "select * from user")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(users))
+}
+
+func TestQueryTx_Bind(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	err := mc.Transaction(func(tx *sql.Tx) error {
+		_, err := QueryTx[int64](mc, tx, "select id from user where id in (?)", []int64{1, 2})
+		assert.NotNil(t, err)
+		_, err = QueryScalarTx[int64](mc, tx, "select count(1) from user where name = :name", map[string]interface{}{"name": "test"})
+		assert.NotNil(t, err)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"select id from user where id in (?, ?)",
+		"select count(1) from user where name = ?",
+		"COMMIT",
+	}, d.queries)
+}
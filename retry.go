@@ -0,0 +1,90 @@
+package mysqlclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	erLockWaitTimeout = 1205
+	erLockDeadlock    = 1213
+)
+
+// RetryPolicy decides how often and how fast a failed transaction is re-run.
+type RetryPolicy struct {
+	// MaxAttempts counts the first run; values below 1 mean a single run
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for every further retry up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Retryable classifies errors; nil means IsRetryable
+	Retryable func(err error) bool
+	// OnRetry, when set, is called before every retry, e.g. to count retries in metrics
+	OnRetry func(attempt int, err error)
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, backing off 50ms then 100ms with jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+}
+
+// IsRetryable reports whether err is an InnoDB deadlock (1213) or lock wait timeout (1205),
+// after which the whole transaction may safely be run again.
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == erLockDeadlock || mysqlErr.Number == erLockWaitTimeout
+}
+
+func (p *RetryPolicy) run(ctx context.Context, logger Logger, f func() error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) {
+			if err != nil && attempt > 1 {
+				logger.Printf("mysqlclient: transaction failed after %d attempts: %v", attempt, err)
+			}
+			return err
+		}
+		logger.Printf("mysqlclient: retrying transaction, attempt %d of %d failed: %v", attempt, p.MaxAttempts, err)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err)
+		}
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt: BaseDelay doubled per attempt, capped at MaxDelay,
+// with jitter drawing it from the upper half so that deadlocked transactions do not collide again.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,83 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&mysql.MySQLError{Number: 1213}))
+	assert.True(t, IsRetryable(fmt.Errorf("update: %w", &mysql.MySQLError{Number: 1205})))
+	assert.False(t, IsRetryable(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, IsRetryable(errors.New("deadlock")))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		delay := policy.backoff(attempt)
+		assert.True(t, delay >= max/2 && delay <= max, "attempt %d: %v", attempt, delay)
+	}
+	assert.EqualValues(t, 0, (&RetryPolicy{}).backoff(1))
+}
+
+func TestRetryPolicy_Run(t *testing.T) {
+	logger := &recordLogger{}
+	var retries []int
+	policy := RetryPolicy{MaxAttempts: 3, OnRetry: func(attempt int, err error) {
+		retries = append(retries, attempt)
+	}}
+	deadlock := &mysql.MySQLError{Number: 1213}
+
+	calls := 0
+	err := policy.run(context.Background(), logger, func() error {
+		calls++
+		if calls < 3 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, calls)
+	assert.EqualValues(t, []int{1, 2}, retries)
+	assert.EqualValues(t, 2, len(logger.messages))
+
+	calls = 0
+	err = policy.run(context.Background(), logger, func() error {
+		calls++
+		return deadlock
+	})
+	assert.Equal(t, deadlock, err)
+	assert.EqualValues(t, 3, calls)
+
+	calls = 0
+	err = policy.run(context.Background(), logger, func() error {
+		calls++
+		return errors.New("not retryable")
+	})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 1, calls)
+}
+
+func TestTransaction_Retry(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db, logger: &recordLogger{}}}
+	calls := 0
+	err := mc.Transaction(func(tx *sql.Tx) error {
+		calls++
+		if calls == 1 {
+			return &mysql.MySQLError{Number: 1205}
+		}
+		return nil
+	}, WithRetry(RetryPolicy{MaxAttempts: 2}))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, calls)
+	assert.EqualValues(t, []string{"ROLLBACK", "COMMIT"}, d.queries)
+}
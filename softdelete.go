@@ -0,0 +1,293 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DeleteStruct deletes the row of table whose primary key matches input, a struct pointer.
+// When the struct has a softdelete field the row is kept and the field is set instead:
+// a flag column becomes 1 and a time column becomes the current time, both in the row and in input.
+// Rows already soft-deleted are not touched again.
+func (mc *MysqlClient) DeleteStruct(table string, input interface{}) (int64, error) {
+	return mc.deleteStruct(context.Background(), mc.GetDB(), table, input)
+}
+
+func (mc *MysqlClient) DeleteStructContext(ctx context.Context, table string, input interface{}) (int64, error) {
+	return mc.deleteStruct(ctx, mc.session(ctx), table, input)
+}
+
+func (mc *MysqlClient) DeleteStructTx(tx *sql.Tx, table string, input interface{}) (int64, error) {
+	return mc.deleteStruct(context.Background(), tx, table, input)
+}
+
+func (mc *MysqlClient) DeleteStructTxContext(ctx context.Context, tx *sql.Tx, table string, input interface{}) (int64, error) {
+	return mc.deleteStruct(ctx, tx, table, input)
+}
+
+// DeleteByPrimaryKey deletes the row of table with the given primary key values, given in field order.
+// model is a struct or struct pointer whose tags describe the table, as for DeleteStruct.
+func (mc *MysqlClient) DeleteByPrimaryKey(table string, model interface{}, keys ...interface{}) (int64, error) {
+	return mc.DeleteByPrimaryKeyContext(context.Background(), table, model, keys...)
+}
+
+func (mc *MysqlClient) DeleteByPrimaryKeyContext(ctx context.Context, table string, model interface{}, keys ...interface{}) (int64, error) {
+	structT := reflect.TypeOf(model)
+	if structT != nil && structT.Kind() == reflect.Ptr {
+		structT = structT.Elem()
+	}
+	if structT == nil || !isMappableStruct(structT) {
+		return 0, fmt.Errorf("%v must be a struct or struct pointer", model)
+	}
+	keyFields, _, err := updateKeys(structT)
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) != len(keyFields) {
+		return 0, fmt.Errorf("%d key values for %d pk fields of %v", len(keys), len(keyFields), structT)
+	}
+	return mc.delete(ctx, mc.session(ctx), table, structT, keyFields, keys, reflect.Value{})
+}
+
+// HardDelete physically deletes the row of table whose primary key matches input, ignoring any softdelete field.
+func (mc *MysqlClient) HardDelete(table string, input interface{}) (int64, error) {
+	return mc.Unscoped().DeleteStruct(table, input)
+}
+
+func (mc *MysqlClient) HardDeleteContext(ctx context.Context, table string, input interface{}) (int64, error) {
+	return mc.Unscoped().DeleteStructContext(ctx, table, input)
+}
+
+func (mc *MysqlClient) deleteStruct(ctx context.Context, e execer, table string, input interface{}) (int64, error) {
+	structV, err := structValue(input)
+	if err != nil {
+		return 0, err
+	}
+	keyFields, _, err := updateKeys(structV.Type())
+	if err != nil {
+		return 0, err
+	}
+	keys := make([]interface{}, len(keyFields))
+	for i, key := range keyFields {
+		keys[i], err = fieldValue(structV, key)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return mc.delete(ctx, e, table, structV.Type(), keyFields, keys, structV)
+}
+
+// delete removes or soft-deletes one row by key; structV, when valid, receives the soft-delete value.
+func (mc *MysqlClient) delete(ctx context.Context, e execer, table string, structT reflect.Type, keyFields []fieldInfo, keys []interface{}, structV reflect.Value) (int64, error) {
+	var conditions []string
+	for _, key := range keyFields {
+		conditions = append(conditions, quoteIdentifier(key.column)+" = ?")
+	}
+	info, soft := softDeleteField(structT)
+	var query string
+	var args []interface{}
+	var deleted interface{}
+	if soft && !mc.unscoped {
+		deleted = int64(1)
+		if isTimeField(structT, info) {
+			deleted = time.Now()
+		}
+		conditions = append(conditions, notDeleted(structT, info))
+		query = fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s", quoteTable(table), quoteIdentifier(info.column), strings.Join(conditions, " AND "))
+		args = append(args, deleted)
+	} else {
+		query = fmt.Sprintf("DELETE FROM %s WHERE %s", quoteTable(table), strings.Join(conditions, " AND "))
+	}
+	args, err := mc.getConverters().encodeArgs(append(args, keys...))
+	if err != nil {
+		return 0, err
+	}
+	result, err := e.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if deleted != nil && rowsAffected > 0 && structV.IsValid() {
+		field, _ := fieldByIndex(structV, info.index, true)
+		err = convertAssign(field, deleted)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return rowsAffected, nil
+}
+
+// scoped filters soft-deleted rows out of sql, the query of a find decoding into t, by adding the
+// softdelete condition to its WHERE clause. Only single-table SELECTs can be rewritten; for queries with joins,
+// unions, derived tables or index hints it returns an error rather than let deleted rows through.
+func (mc *MysqlClient) scoped(sql string, t reflect.Type) (string, error) {
+	if mc.unscoped {
+		return sql, nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !isMappableStruct(t) {
+		return sql, nil
+	}
+	info, ok := softDeleteField(t)
+	if !ok {
+		return sql, nil
+	}
+	scopedSQL, ok := andWhere(sql, notDeleted(t, info))
+	if !ok {
+		return "", fmt.Errorf("%v has a softdelete field that can not be filtered from this query; add %s to it and use Unscoped", t, notDeleted(t, info))
+	}
+	return scopedSQL, nil
+}
+
+// sqlWord is a keyword or identifier outside parentheses, spanning query[start:end].
+type sqlWord struct {
+	text  string
+	start int
+	end   int
+}
+
+// andWhere ANDs condition into the WHERE clause of a single-table SELECT, adding the clause when missing.
+// It reports false for statements it cannot rewrite safely.
+func andWhere(query string, condition string) (string, bool) {
+	query = trimSQL(query)
+	var words []sqlWord
+	var marks []int
+	depth := 0
+	walkSQL(query, func(i int) int {
+		switch c := query[i]; {
+		case c == '(':
+			if depth == 0 {
+				marks = append(marks, i)
+			}
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			marks = append(marks, i)
+		case isNameStart(c) && (i == 0 || !isWordByte(query[i-1])):
+			end := i + 1
+			for end < len(query) && isWordByte(query[end]) {
+				end++
+			}
+			if depth == 0 && (i == 0 || (query[i-1] != '.' && query[i-1] != ':')) {
+				words = append(words, sqlWord{text: strings.ToUpper(query[i:end]), start: i, end: end})
+			}
+			return end - 1
+		}
+		return i
+	})
+	if len(words) == 0 || words[0].text != "SELECT" {
+		return query, false
+	}
+	from := -1
+	for i, word := range words {
+		if word.text == "UNION" {
+			return query, false
+		}
+		if word.text == "FROM" && from < 0 {
+			from = i
+		}
+	}
+	if from < 0 {
+		return query, false
+	}
+	fromEnd, where := len(query), -1
+	for i := from + 1; i < len(words); i++ {
+		if words[i].text == "WHERE" {
+			fromEnd, where = words[i].start, i
+			break
+		}
+		if isClauseWord(words[i].text) {
+			fromEnd = words[i].start
+			break
+		}
+		if strings.Contains(words[i].text, "JOIN") {
+			return query, false
+		}
+	}
+	for _, mark := range marks {
+		if mark > words[from].start && mark < fromEnd {
+			return query, false
+		}
+	}
+	if where < 0 {
+		head, sep := strings.TrimSpace(query[:fromEnd]), " "
+		if mayComment(head) {
+			sep = "\n"
+		}
+		return head + sep + "WHERE " + condition + sqlTail(query[fromEnd:]), true
+	}
+	whereEnd := len(query)
+	for i := where + 1; i < len(words); i++ {
+		if isClauseWord(words[i].text) {
+			whereEnd = words[i].start
+			break
+		}
+	}
+	body := strings.TrimSpace(query[words[where].end:whereEnd])
+	if mayComment(body) {
+		body += "\n"
+	}
+	return query[:words[where].end] + " (" + body + ") AND " + condition + sqlTail(query[whereEnd:]), true
+}
+
+// mayComment reports whether fragment may end in a -- or # comment that would swallow
+// whatever follows on the same line.
+func mayComment(fragment string) bool {
+	return strings.Contains(fragment, "--") || strings.Contains(fragment, "#")
+}
+
+func sqlTail(tail string) string {
+	tail = strings.TrimSpace(tail)
+	if tail == "" {
+		return ""
+	}
+	return " " + tail
+}
+
+func isWordByte(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
+
+// isClauseWord reports whether word starts a clause that follows FROM and WHERE.
+func isClauseWord(word string) bool {
+	switch word {
+	case "GROUP", "HAVING", "WINDOW", "ORDER", "LIMIT", "FOR", "LOCK", "INTO", "PROCEDURE":
+		return true
+	}
+	return false
+}
+
+func softDeleteField(structT reflect.Type) (fieldInfo, bool) {
+	for _, info := range cachedStructColumns(structT) {
+		if info.softDelete {
+			return info, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+// isTimeField tells a deleted_at timestamp (time.Time or *time.Time) from a deleted flag.
+func isTimeField(structT reflect.Type, info fieldInfo) bool {
+	fieldT := structT.FieldByIndex(info.index).Type
+	if fieldT.Kind() == reflect.Ptr {
+		fieldT = fieldT.Elem()
+	}
+	return fieldT == timeType
+}
+
+func notDeleted(structT reflect.Type, info fieldInfo) string {
+	if isTimeField(structT, info) {
+		return quoteIdentifier(info.column) + " IS NULL"
+	}
+	return quoteIdentifier(info.column) + " = 0"
+}
@@ -0,0 +1,109 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type flagDeleted struct {
+	Id       int64  `db:"id,pk"`
+	Name     string `db:"name"`
+	IsDelete bool   `db:"is_delete,softdelete"`
+}
+
+type timeDeleted struct {
+	Id        int64      `db:"id,pk"`
+	DeletedAt *time.Time `db:"deleted_at,softdelete"`
+}
+
+func TestScoped(t *testing.T) {
+	mc := &MysqlClient{}
+	scoped := func(sql string, model interface{}) string {
+		sql, err := mc.scoped(sql, reflect.TypeOf(model))
+		assert.Nil(t, err)
+		return sql
+	}
+	assert.EqualValues(t, "select * from userinfo WHERE `is_delete` = 0", scoped("select * from userinfo;", flagDeleted{}))
+	assert.EqualValues(t, "select * from userinfo WHERE `deleted_at` IS NULL", scoped("select * from userinfo", &timeDeleted{}))
+	assert.EqualValues(t, "select * from userinfo", scoped("select * from userinfo", crudUser{}))
+	assert.EqualValues(t, "select count(1) from userinfo", scoped("select count(1) from userinfo", int64(0)))
+
+	_, err := mc.scoped("select u.* from userinfo u join role r on r.user_id = u.id", reflect.TypeOf(flagDeleted{}))
+	assert.NotNil(t, err)
+	mc = mc.Unscoped()
+	assert.EqualValues(t, "select * from userinfo", scoped("select * from userinfo", flagDeleted{}))
+	assert.EqualValues(t, "select u.* from userinfo u join role r on r.user_id = u.id", scoped("select u.* from userinfo u join role r on r.user_id = u.id", flagDeleted{}))
+}
+
+func TestAndWhere(t *testing.T) {
+	cond := "`is_delete` = 0"
+	for query, expected := range map[string]string{
+		"select id, name from userinfo":                                     "select id, name from userinfo WHERE `is_delete` = 0",
+		"select u.id from userinfo u order by u.id limit 10":                "select u.id from userinfo u WHERE `is_delete` = 0 order by u.id limit 10",
+		"select * from userinfo where a = 1 or b = 'x where' order by id":   "select * from userinfo where (a = 1 or b = 'x where') AND `is_delete` = 0 order by id",
+		"select * from userinfo where id in (select id from t order by id)": "select * from userinfo where (id in (select id from t order by id)) AND `is_delete` = 0",
+		"select * from userinfo where sort = :order for update":             "select * from userinfo where (sort = :order) AND `is_delete` = 0 for update",
+		"select * from userinfo -- all":                                     "select * from userinfo -- all\nWHERE `is_delete` = 0",
+	} {
+		scoped, ok := andWhere(query, cond)
+		assert.True(t, ok, query)
+		assert.EqualValues(t, expected, scoped)
+	}
+	for _, query := range []string{
+		"select * from userinfo u join role r on r.user_id = u.id",
+		"select * from userinfo u, role r where r.user_id = u.id",
+		"select * from (select * from userinfo) t",
+		"select * from userinfo union select * from archive",
+		"select * from userinfo force index (idx_name)",
+		"select 1",
+		"show tables",
+	} {
+		_, ok := andWhere(query, cond)
+		assert.False(t, ok, query)
+	}
+}
+
+func TestCursorScan_SoftDelete(t *testing.T) {
+	cursor := &Cursor{mapper: (&MysqlClient{}).mapper()}
+	assert.NotNil(t, cursor.Scan(&flagDeleted{}))
+	var user *flagDeleted
+	assert.NotNil(t, cursor.Scan(&user))
+}
+
+func TestSoftDeleteField(t *testing.T) {
+	info, ok := softDeleteField(reflect.TypeOf(flagDeleted{}))
+	assert.True(t, ok)
+	assert.EqualValues(t, "is_delete", info.column)
+	assert.False(t, isTimeField(reflect.TypeOf(flagDeleted{}), info))
+
+	info, ok = softDeleteField(reflect.TypeOf(timeDeleted{}))
+	assert.True(t, ok)
+	assert.True(t, isTimeField(reflect.TypeOf(timeDeleted{}), info))
+
+	_, ok = softDeleteField(reflect.TypeOf(crudUser{}))
+	assert.False(t, ok)
+}
+
+func TestMysqlClient_DeleteStruct(t *testing.T) {
+	once.Do(setup)
+	user := &flagDeleted{Name: "soft delete"}
+	id, err := mysqlClient.Insert("insert into userinfo (name, is_delete) values (?, 0)", user.Name)
+	assert.Nil(t, err)
+	user.Id = id
+	rowsAffected, err := mysqlClient.DeleteStruct("userinfo", user)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, rowsAffected)
+	assert.True(t, user.IsDelete)
+
+	var found flagDeleted
+	err = mysqlClient.FindFirst("select id, name, is_delete from userinfo where id = ?", &found, id)
+	assert.Equal(t, RecordNotFoundError, err)
+	err = mysqlClient.Unscoped().FindFirst("select id, name, is_delete from userinfo where id = ?", &found, id)
+	assert.Nil(t, err)
+
+	rowsAffected, err = mysqlClient.HardDelete("userinfo", user)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, rowsAffected)
+}
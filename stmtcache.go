@@ -0,0 +1,227 @@
+package mysqlclient
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// erMaxPreparedStmtCountReached is returned by the server once max_prepared_stmt_count statements are open.
+const erMaxPreparedStmtCountReached = 1461
+
+// StmtCacheStats reports the activity of the prepared statement cache.
+type StmtCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// stmtCache is an LRU of statements prepared on the pool, keyed by SQL text.
+// A statement is only closed once it has been evicted and every caller using it has released it.
+type stmtCache struct {
+	mu        sync.Mutex
+	capacity  int
+	lru       *list.List
+	entries   map[string]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// prepare returns the cached statement for query, preparing it on a miss, and a release func the caller
+// must call once done with it. Without a cache the statement is prepared for this call only.
+func (c *stmtCache) prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, func(), error) {
+	if c == nil || c.capacity <= 0 {
+		return prepareOnce(ctx, db, query)
+	}
+	if stmt, release, ok := c.lookup(query); ok {
+		return stmt, release, nil
+	}
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == erMaxPreparedStmtCountReached {
+			// the server is out of statement handles: give ours back and run this one uncached
+			c.purge()
+			return prepareOnce(ctx, db, query)
+		}
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[query]; ok {
+		// another caller prepared the same query meanwhile; keep theirs
+		entry := element.Value.(*stmtEntry)
+		entry.refs++
+		stmt.Close()
+		return entry.stmt, func() { c.release(entry) }, nil
+	}
+	entry := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		c.evict(c.lru.Back())
+	}
+	return stmt, func() { c.release(entry) }, nil
+}
+
+// lookup returns the cached statement for query, counting a hit or a miss.
+func (c *stmtCache) lookup(query string) (*sql.Stmt, func(), bool) {
+	if c == nil || c.capacity <= 0 {
+		return nil, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[query]
+	if !ok {
+		c.misses++
+		return nil, nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(element)
+	entry := element.Value.(*stmtEntry)
+	entry.refs++
+	return entry.stmt, func() { c.release(entry) }, true
+}
+
+func prepareOnce(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, func(), error) {
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stmt, func() { stmt.Close() }, nil
+}
+
+func (c *stmtCache) release(entry *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// evict must be called with c.mu held.
+func (c *stmtCache) evict(element *list.Element) {
+	entry := c.lru.Remove(element).(*stmtEntry)
+	delete(c.entries, entry.query)
+	c.evictions++
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.stmt.Close()
+	}
+}
+
+// purge evicts every statement, closing those not in use.
+func (c *stmtCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
+
+// limit shrinks the capacity so that the cache, prepared on up to connections connections,
+// stays within half of the server's max_prepared_stmt_count and leaves the rest to other clients.
+func (c *stmtCache) limit(maxPreparedStmtCount int64, connections int) {
+	if c == nil || maxPreparedStmtCount < 0 {
+		return
+	}
+	if connections < 1 {
+		connections = 1
+	}
+	limit := int(maxPreparedStmtCount / 2 / int64(connections))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit < c.capacity {
+		c.capacity = limit
+	}
+	for c.lru.Len() > c.capacity {
+		c.evict(c.lru.Back())
+	}
+}
+
+func (c *stmtCache) stats() StmtCacheStats {
+	if c == nil {
+		return StmtCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return StmtCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.lru.Len(),
+		Capacity:  c.capacity,
+	}
+}
+
+// StmtCacheStats returns the hit, miss and eviction counters of the prepared statement cache.
+func (mc *MysqlClient) StmtCacheStats() StmtCacheStats {
+	return mc.stmts.stats()
+}
+
+// TxStmt returns the cached statement for query rebound to tx with tx.Stmt, or on a miss
+// a statement prepared on tx itself, since the pool may have no connection left to prepare on.
+// The returned statement is closed when tx commits or rolls back.
+func (mc *MysqlClient) TxStmt(tx *sql.Tx, query string) (*sql.Stmt, error) {
+	return mc.TxStmtContext(context.Background(), tx, query)
+}
+
+func (mc *MysqlClient) TxStmtContext(ctx context.Context, tx *sql.Tx, query string) (*sql.Stmt, error) {
+	stmt, release, ok := mc.stmts.lookup(query)
+	if !ok {
+		return tx.PrepareContext(ctx, query)
+	}
+	// tx.Stmt keeps the pooled statement open until the transaction's copy is closed
+	defer release()
+	return tx.StmtContext(ctx, stmt), nil
+}
+
+// prepare returns the cached statement for query, rebound to the transaction carried by ctx when there is one.
+func (mc *MysqlClient) prepare(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	tx, ok := mc.txFromContext(ctx)
+	if !ok {
+		return mc.stmts.prepare(ctx, mc.GetDB(), query)
+	}
+	stmt, err := mc.TxStmtContext(ctx, tx.Tx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stmt, func() { stmt.Close() }, nil
+}
+
+// initialStmtCache sizes the cache against the server's max_prepared_stmt_count.
+func (mc *MysqlClient) initialStmtCache(ctx context.Context) {
+	mc.stmts = newStmtCache(mc.config.stmtCacheSize)
+	if mc.config.stmtCacheSize <= 0 {
+		return
+	}
+	maxPreparedStmtCount, err := QueryScalarContext[int64](ctx, mc, "SELECT @@max_prepared_stmt_count")
+	if err != nil {
+		mc.config.logger.Printf("mysqlclient: reading max_prepared_stmt_count: %v", err)
+		return
+	}
+	mc.stmts.limit(maxPreparedStmtCount, mc.GetDB().Stats().MaxOpenConnections)
+}
@@ -0,0 +1,190 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingDriver prepares no-op statements, counts the ones still open and records every prepared query.
+type countingDriver struct {
+	open        int64
+	mu          sync.Mutex
+	queries     []string
+	rollbackErr error
+}
+
+type countingConn struct {
+	d *countingDriver
+}
+
+type countingStmt struct {
+	d *countingDriver
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	return &countingConn{d: d}, nil
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(&c.d.open, 1)
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.mu.Unlock()
+	return &countingStmt{d: c.d}, nil
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *countingConn) Commit() error {
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, "COMMIT")
+	c.d.mu.Unlock()
+	return nil
+}
+
+func (c *countingConn) Rollback() error {
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, "ROLLBACK")
+	c.d.mu.Unlock()
+	return c.d.rollbackErr
+}
+
+func (s *countingStmt) Close() error {
+	atomic.AddInt64(&s.d.open, -1)
+	return nil
+}
+
+func (s *countingStmt) NumInput() int {
+	return -1
+}
+
+func (s *countingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return countingResult{}, nil
+}
+
+func (s *countingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+type countingResult struct{}
+
+func (countingResult) LastInsertId() (int64, error) {
+	return 7, nil
+}
+
+func (countingResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+func openCountingDB(t *testing.T) (*sql.DB, *countingDriver) {
+	d := &countingDriver{}
+	db := sql.OpenDB(&countingConnector{d: d})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+type countingConnector struct {
+	d *countingDriver
+}
+
+func (c *countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.d.Open("")
+}
+
+func (c *countingConnector) Driver() driver.Driver {
+	return c.d
+}
+
+func TestStmtCache(t *testing.T) {
+	db, d := openCountingDB(t)
+	cache := newStmtCache(2)
+	for _, query := range []string{"q1", "q2", "q1", "q3"} {
+		stmt, release, err := cache.prepare(context.Background(), db, query)
+		assert.Nil(t, err)
+		_, err = stmt.Exec()
+		assert.Nil(t, err)
+		release()
+	}
+	assert.EqualValues(t, StmtCacheStats{Hits: 1, Misses: 3, Evictions: 1, Size: 2, Capacity: 2}, cache.stats())
+	assert.EqualValues(t, 2, atomic.LoadInt64(&d.open))
+
+	// q1 was used more recently than q2, so q2 was evicted
+	_, release, err := cache.prepare(context.Background(), db, "q1")
+	assert.Nil(t, err)
+	release()
+	assert.EqualValues(t, 2, cache.stats().Hits)
+
+	cache.purge()
+	assert.EqualValues(t, 0, cache.stats().Size)
+	assert.EqualValues(t, 0, atomic.LoadInt64(&d.open))
+}
+
+func TestStmtCache_EvictInUse(t *testing.T) {
+	db, d := openCountingDB(t)
+	cache := newStmtCache(1)
+	stmt, release, err := cache.prepare(context.Background(), db, "q1")
+	assert.Nil(t, err)
+	_, release2, err := cache.prepare(context.Background(), db, "q2")
+	assert.Nil(t, err)
+	release2()
+
+	// q1 is evicted but stays open until released
+	_, err = stmt.Exec()
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt64(&d.open))
+	release()
+	assert.EqualValues(t, 1, atomic.LoadInt64(&d.open))
+}
+
+func TestStmtCache_Limit(t *testing.T) {
+	cache := newStmtCache(100)
+	cache.limit(16382, 0)
+	assert.EqualValues(t, 100, cache.stats().Capacity)
+	cache.limit(1000, 10)
+	assert.EqualValues(t, 50, cache.stats().Capacity)
+}
+
+func TestStmtCache_Disabled(t *testing.T) {
+	db, d := openCountingDB(t)
+	var cache *stmtCache
+	_, release, err := cache.prepare(context.Background(), db, "q1")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt64(&d.open))
+	release()
+	assert.EqualValues(t, 0, atomic.LoadInt64(&d.open))
+	assert.EqualValues(t, StmtCacheStats{}, cache.stats())
+}
+
+func TestTxStmt(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}, stmts: newStmtCache(10)}
+	_, release, err := mc.stmts.prepare(context.Background(), db, "q1")
+	assert.Nil(t, err)
+	release()
+	for _, query := range []string{"q1", "q2"} {
+		err = mc.Transaction(func(tx *sql.Tx) error {
+			stmt, err := mc.TxStmt(tx, query)
+			if err != nil {
+				return err
+			}
+			_, err = stmt.Exec()
+			return err
+		})
+		assert.Nil(t, err)
+	}
+	assert.EqualValues(t, StmtCacheStats{Hits: 1, Misses: 2, Size: 1, Capacity: 10}, mc.StmtCacheStats())
+	// only the cached q1 stays prepared
+	assert.EqualValues(t, 1, atomic.LoadInt64(&d.open))
+}
@@ -0,0 +1,221 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type txConfig struct {
+	options          sql.TxOptions
+	timeout          time.Duration
+	lockWaitTimeout  time.Duration
+	maxExecutionTime time.Duration
+	retry            *RetryPolicy
+}
+
+type TxOption func(*txConfig)
+
+// Isolation sets the isolation level, e.g. sql.LevelReadCommitted, sql.LevelRepeatableRead or sql.LevelSerializable.
+func Isolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		c.options.Isolation = level
+	}
+}
+
+// ReadOnly starts the transaction with START TRANSACTION READ ONLY.
+func ReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.options.ReadOnly = true
+	}
+}
+
+// TxTimeout bounds the whole transaction; once it expires the transaction is rolled back.
+func TxTimeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.timeout = timeout
+	}
+}
+
+// LockWaitTimeout sets innodb_lock_wait_timeout, rounded up to whole seconds, for the transaction's statements.
+func LockWaitTimeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.lockWaitTimeout = timeout
+	}
+}
+
+// MaxExecutionTime sets max_execution_time, in milliseconds, for the transaction's SELECT statements.
+func MaxExecutionTime(timeout time.Duration) TxOption {
+	return func(c *txConfig) {
+		c.maxExecutionTime = timeout
+	}
+}
+
+// WithRetry re-runs the whole transaction when it fails with an error policy classifies as retryable,
+// by default an InnoDB deadlock or lock wait timeout.
+func WithRetry(policy RetryPolicy) TxOption {
+	return func(c *txConfig) {
+		c.retry = &policy
+	}
+}
+
+func newTxConfig(opts []TxOption) *txConfig {
+	config := &txConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// sessionSQL returns the SET SESSION statement for the timeouts, or "" when none is set.
+func (c *txConfig) sessionSQL() string {
+	var settings []string
+	if c.lockWaitTimeout > 0 {
+		seconds := int64((c.lockWaitTimeout + time.Second - 1) / time.Second)
+		settings = append(settings, fmt.Sprintf("innodb_lock_wait_timeout = %d", seconds))
+	}
+	if c.maxExecutionTime > 0 {
+		milliseconds := int64((c.maxExecutionTime + time.Millisecond - 1) / time.Millisecond)
+		settings = append(settings, fmt.Sprintf("max_execution_time = %d", milliseconds))
+	}
+	if len(settings) == 0 {
+		return ""
+	}
+	return "SET SESSION " + strings.Join(settings, ", ")
+}
+
+// beginTx starts a transaction with config. Session timeouts are set on a dedicated connection
+// and restored once the returned end func runs, so that they never leak into the pool.
+func (mc *MysqlClient) beginTx(ctx context.Context, config *txConfig) (*sql.Tx, func(), error) {
+	setSQL := config.sessionSQL()
+	if setSQL == "" {
+		tx, err := mc.GetDB().BeginTx(ctx, &config.options)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx, func() {}, nil
+	}
+	conn, err := mc.GetDB().Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var lockWaitTimeout, maxExecutionTime int64
+	err = conn.QueryRowContext(ctx, "SELECT @@SESSION.innodb_lock_wait_timeout, @@SESSION.max_execution_time").Scan(&lockWaitTimeout, &maxExecutionTime)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	_, err = conn.ExecContext(ctx, setSQL)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	end := func() {
+		// restore even when ctx is done; a connection left with our settings is discarded
+		restoreSQL := fmt.Sprintf("SET SESSION innodb_lock_wait_timeout = %d, max_execution_time = %d", lockWaitTimeout, maxExecutionTime)
+		_, err := conn.ExecContext(context.Background(), restoreSQL)
+		if err != nil {
+			mc.mapper().getLogger().Printf("mysqlclient: restoring session timeouts: %v", err)
+			conn.Raw(func(interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+		conn.Close()
+	}
+	tx, err := conn.BeginTx(ctx, &config.options)
+	if err != nil {
+		end()
+		return nil, nil, err
+	}
+	return tx, end, nil
+}
+
+type txKey struct{}
+
+// Tx is a transaction handle whose Transaction nests through savepoints: an inner error rolls back
+// to the savepoint only, and the transaction commits once the outermost callback returns nil.
+type Tx struct {
+	*sql.Tx
+	mc         *MysqlClient
+	ctx        context.Context
+	savepoints *int
+}
+
+type TxCallback func(*Tx) error
+
+// WithTx runs callback in a transaction like Transaction, handing it a Tx that can nest.
+func (mc *MysqlClient) WithTx(callback TxCallback, opts ...TxOption) error {
+	return mc.WithTxContext(context.Background(), callback, opts...)
+}
+
+// WithTxContext is WithTx bound to ctx. When ctx comes from a Tx of this client, callback runs in a savepoint
+// of that transaction and opts are ignored, since the transaction has already begun.
+func (mc *MysqlClient) WithTxContext(ctx context.Context, callback TxCallback, opts ...TxOption) error {
+	if parent, ok := mc.txFromContext(ctx); ok {
+		return parent.savepoint(ctx, callback)
+	}
+	return mc.transaction(ctx, newTxConfig(opts), func(ctx context.Context, sqlTx *sql.Tx) error {
+		tx := &Tx{Tx: sqlTx, mc: mc, savepoints: new(int)}
+		tx.ctx = context.WithValue(ctx, txKey{}, tx)
+		return callback(tx)
+	})
+}
+
+// Context returns the transaction's context. The client's ...Context methods given it run their statements
+// in this transaction, and those taking a transaction callback nest it in a savepoint.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// Transaction runs callback inside a savepoint of tx.
+func (tx *Tx) Transaction(callback TxCallback) error {
+	return tx.savepoint(tx.ctx, callback)
+}
+
+func (tx *Tx) savepoint(ctx context.Context, callback TxCallback) error {
+	*tx.savepoints++
+	name := fmt.Sprintf("sp_%d", *tx.savepoints)
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return err
+	}
+	child := &Tx{Tx: tx.Tx, mc: tx.mc, savepoints: tx.savepoints}
+	child.ctx = context.WithValue(ctx, txKey{}, child)
+	err = callback(child)
+	if err != nil {
+		_, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		if rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// sqlSession is what a call runs its statements on: the pool, a pinned connection or a transaction.
+type sqlSession interface {
+	execer
+	queryer
+}
+
+// session returns the transaction carried by ctx when it belongs to this client, and the pool otherwise.
+func (mc *MysqlClient) session(ctx context.Context) sqlSession {
+	if tx, ok := mc.txFromContext(ctx); ok {
+		return tx.Tx
+	}
+	return mc.GetDB()
+}
+
+// txFromContext returns the Tx carried by ctx when it belongs to this client's pool.
+func (mc *MysqlClient) txFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*Tx)
+	if !ok || tx.mc.GetDB() != mc.GetDB() {
+		return nil, false
+	}
+	return tx, true
+}
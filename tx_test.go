@@ -0,0 +1,164 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTxConfig(t *testing.T) {
+	config := newTxConfig([]TxOption{Isolation(sql.LevelSerializable), ReadOnly()})
+	assert.EqualValues(t, sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}, config.options)
+	assert.EqualValues(t, "", config.sessionSQL())
+
+	config = newTxConfig([]TxOption{LockWaitTimeout(1500 * time.Millisecond), MaxExecutionTime(200 * time.Millisecond)})
+	assert.EqualValues(t, "SET SESSION innodb_lock_wait_timeout = 2, max_execution_time = 200", config.sessionSQL())
+}
+
+func TestGetTransaction_Timeout(t *testing.T) {
+	db, _ := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	_, err := mc.GetTransaction(LockWaitTimeout(time.Second))
+	assert.NotNil(t, err)
+	tx, err := mc.GetTransaction()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback())
+}
+
+func TestMysqlClient_TransactionOptions(t *testing.T) {
+	once.Do(setup)
+	err := mysqlClient.Transaction(func(tx *sql.Tx) error {
+		var lockWaitTimeout int64
+		err := tx.QueryRow("SELECT @@SESSION.innodb_lock_wait_timeout").Scan(&lockWaitTimeout)
+		if err != nil {
+			return err
+		}
+		assert.EqualValues(t, 3, lockWaitTimeout)
+		_, err = tx.Exec("update userinfo set age = age where id = 1")
+		return err
+	}, Isolation(sql.LevelReadCommitted), LockWaitTimeout(3*time.Second))
+	assert.Nil(t, err)
+
+	err = mysqlClient.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec("update userinfo set age = age where id = 1")
+		return err
+	}, ReadOnly())
+	assert.NotNil(t, err)
+}
+
+func TestWithTx_Savepoints(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	innerErr := errors.New("inner")
+	err := mc.WithTx(func(tx *Tx) error {
+		err := tx.Transaction(func(tx *Tx) error {
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		err = mc.TransactionContext(tx.Context(), func(tx *sql.Tx) error {
+			return innerErr
+		})
+		assert.Equal(t, innerErr, err)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"SAVEPOINT sp_1",
+		"RELEASE SAVEPOINT sp_1",
+		"SAVEPOINT sp_2",
+		"ROLLBACK TO SAVEPOINT sp_2",
+		"COMMIT",
+	}, d.queries)
+}
+
+func TestWithTx_ContextStatements(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db, inListSize: 2}}
+	err := mc.WithTx(func(tx *Tx) error {
+		_, err := mc.DeleteContext(tx.Context(), "delete from user where id in (?)", []int64{1, 2})
+		if err != nil {
+			return err
+		}
+		_, err = mc.DeleteContext(tx.Context(), "delete from user where id in (?)", []int64{1, 2, 3})
+		if err != nil {
+			return err
+		}
+		_, err = mc.CountContext(tx.Context(), "select count(1) from user")
+		assert.NotNil(t, err)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{
+		"delete from user where id in (?, ?)",
+		"SAVEPOINT sp_1",
+		"delete from user where id in (?, ?)",
+		"delete from user where id in (?)",
+		"RELEASE SAVEPOINT sp_1",
+		"select count(1) from user",
+		"COMMIT",
+	}, d.queries)
+}
+
+func TestWithTx_OtherClient(t *testing.T) {
+	db, d := openCountingDB(t)
+	other, _ := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	_, ok := (&MysqlClient{config: &Config{pool: other}}).txFromContext(context.Background())
+	assert.False(t, ok)
+	err := mc.WithTx(func(tx *Tx) error {
+		_, ok := (&MysqlClient{config: &Config{pool: other}}).txFromContext(tx.Context())
+		assert.False(t, ok)
+		_, ok = mc.txFromContext(tx.Context())
+		assert.True(t, ok)
+		return errors.New("outer")
+	})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []string{"ROLLBACK"}, d.queries)
+}
+
+func TestTransaction_Panic(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	assert.PanicsWithValue(t, "boom", func() {
+		mc.Transaction(func(tx *sql.Tx) error {
+			panic("boom")
+		})
+	})
+	assert.EqualValues(t, []string{"ROLLBACK"}, d.queries)
+	assert.EqualValues(t, 0, db.Stats().InUse)
+}
+
+func TestTransaction_TxDone(t *testing.T) {
+	db, d := openCountingDB(t)
+	mc := &MysqlClient{config: &Config{pool: db}}
+	err := mc.Transaction(func(tx *sql.Tx) error {
+		return tx.Commit()
+	})
+	assert.Equal(t, TxDoneError, err)
+
+	callbackErr := errors.New("callback")
+	err = mc.Transaction(func(tx *sql.Tx) error {
+		tx.Rollback()
+		return callbackErr
+	})
+	assert.True(t, errors.Is(err, callbackErr))
+	assert.True(t, errors.Is(err, TxDoneError))
+	assert.EqualValues(t, []string{"COMMIT", "ROLLBACK"}, d.queries)
+}
+
+func TestTransaction_RollbackError(t *testing.T) {
+	db, d := openCountingDB(t)
+	d.rollbackErr = errors.New("connection lost")
+	mc := &MysqlClient{config: &Config{pool: db}}
+	callbackErr := errors.New("callback")
+	err := mc.Transaction(func(tx *sql.Tx) error {
+		return callbackErr
+	})
+	assert.ErrorIs(t, err, callbackErr)
+	assert.ErrorIs(t, err, d.rollbackErr)
+}
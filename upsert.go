@@ -0,0 +1,269 @@
+package mysqlclient
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type UpsertSyntax int
+
+const (
+	// UpsertValues writes `col` = VALUES(`col`), understood by every MySQL version but deprecated since 8.0.20
+	UpsertValues UpsertSyntax = iota
+	// UpsertRowAlias writes INSERT ... AS new ON DUPLICATE KEY UPDATE `col` = new.`col`, MySQL 8.0.19 and later
+	UpsertRowAlias
+)
+
+// UpsertStatus follows MySQL's affected-rows convention for INSERT ... ON DUPLICATE KEY UPDATE:
+// 1 for an inserted row, 2 for an updated row and 0 for an existing row left unchanged.
+// With dbclient.ClientFoundRows(true) an unchanged row reports 1 and can not be told apart from an insert.
+type UpsertStatus int
+
+const (
+	UpsertUnchanged UpsertStatus = iota
+	UpsertInserted
+	UpsertUpdated
+)
+
+type upsertConfig struct {
+	updateColumns []string
+	syntax        UpsertSyntax
+}
+
+type UpsertOption func(*upsertConfig)
+
+// UpdateColumns lists the columns overwritten on conflict. By default every inserted column
+// except the primary key and auto-increment columns is overwritten.
+func UpdateColumns(columns ...string) UpsertOption {
+	return func(c *upsertConfig) {
+		c.updateColumns = columns
+	}
+}
+
+func Syntax(syntax UpsertSyntax) UpsertOption {
+	return func(c *upsertConfig) {
+		c.syntax = syntax
+	}
+}
+
+// Upsert inserts input, a struct pointer or a map[string]interface{} of columns, into table,
+// updating the existing row when a unique key conflicts. A generated id is written back like InsertStruct.
+func (mc *MysqlClient) Upsert(table string, input interface{}, opts ...UpsertOption) (UpsertStatus, error) {
+	return mc.UpsertContext(context.Background(), table, input, opts...)
+}
+
+func (mc *MysqlClient) UpsertContext(ctx context.Context, table string, input interface{}, opts ...UpsertOption) (UpsertStatus, error) {
+	config := newUpsertConfig(opts)
+	var columns []string
+	var args []interface{}
+	var structV reflect.Value
+	if values, ok := input.(map[string]interface{}); ok {
+		columns, args = mapValues(values)
+	} else {
+		var err error
+		structV, err = structValue(input)
+		if err != nil {
+			return 0, err
+		}
+		columns, args, err = insertValues(structV)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("no columns to upsert into %s", table)
+	}
+	args, err := mc.getConverters().encodeArgs(args)
+	if err != nil {
+		return 0, err
+	}
+	query := upsertSQL(table, columns, config.update(columns, keyColumns(structV)), config.syntax)
+	result, err := mc.session(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	status := UpsertStatus(rowsAffected)
+	if status == UpsertInserted && structV.IsValid() {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		err = setAutoIncrement(structV, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return status, nil
+}
+
+// UpsertBatch upserts input, a slice of structs, struct pointers or map[string]interface{},
+// executing one prepared statement per row inside a single transaction so that every row gets its status.
+// Rows given as maps must all have the same keys. Generated ids are not written back.
+func (mc *MysqlClient) UpsertBatch(table string, input interface{}, opts ...UpsertOption) ([]UpsertStatus, error) {
+	return mc.UpsertBatchContext(context.Background(), table, input, opts...)
+}
+
+func (mc *MysqlClient) UpsertBatchContext(ctx context.Context, table string, input interface{}, opts ...UpsertOption) ([]UpsertStatus, error) {
+	config := newUpsertConfig(opts)
+	var columns []string
+	var argsList [][]interface{}
+	var keys []string
+	if maps, ok := input.([]map[string]interface{}); ok {
+		for i, values := range maps {
+			rowColumns, args := mapValues(values)
+			if i == 0 {
+				columns = rowColumns
+			} else if strings.Join(rowColumns, ",") != strings.Join(columns, ",") {
+				return nil, fmt.Errorf("row %d has columns %v, expected %v", i, rowColumns, columns)
+			}
+			argsList = append(argsList, args)
+		}
+	} else {
+		sliceV := reflect.ValueOf(input)
+		if sliceV.Kind() == reflect.Ptr {
+			sliceV = sliceV.Elem()
+		}
+		if sliceV.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%v must be a slice", input)
+		}
+		structs := make([]reflect.Value, sliceV.Len())
+		for i := range structs {
+			elem := sliceV.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if !elem.IsValid() || !isMappableStruct(elem.Type()) {
+				return nil, fmt.Errorf("row %d must be a struct or a non-nil struct pointer", i)
+			}
+			structs[i] = elem
+		}
+		if len(structs) == 0 {
+			return []UpsertStatus{}, nil
+		}
+		infos := batchColumns(structs[0].Type(), structs)
+		for _, info := range infos {
+			columns = append(columns, info.column)
+		}
+		for _, structV := range structs {
+			args := make([]interface{}, len(infos))
+			for j, info := range infos {
+				value, err := fieldValue(structV, info)
+				if err != nil {
+					return nil, err
+				}
+				args[j] = value
+			}
+			argsList = append(argsList, args)
+		}
+		keys = keyColumns(structs[0])
+	}
+	if len(argsList) == 0 {
+		return []UpsertStatus{}, nil
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns to upsert into %s", table)
+	}
+	query := upsertSQL(table, columns, config.update(columns, keys), config.syntax)
+	var rowsAffected []int64
+	err := mc.TransactionContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		rowsAffected, err = mc.batchExecTx(ctx, tx, query, argsList, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]UpsertStatus, len(rowsAffected))
+	for i, n := range rowsAffected {
+		statuses[i] = UpsertStatus(n)
+	}
+	return statuses, nil
+}
+
+func newUpsertConfig(opts []UpsertOption) *upsertConfig {
+	config := &upsertConfig{syntax: UpsertValues}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// update returns the columns to overwrite on conflict, skipping keys unless they were asked for explicitly.
+func (c *upsertConfig) update(columns []string, keys []string) []string {
+	if c.updateColumns != nil {
+		return c.updateColumns
+	}
+	skip := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		skip[strings.ToLower(key)] = true
+	}
+	var update []string
+	for _, column := range columns {
+		if !skip[strings.ToLower(column)] {
+			update = append(update, column)
+		}
+	}
+	return update
+}
+
+// keyColumns lists the pk and autoincr columns of a struct; maps have none.
+func keyColumns(structV reflect.Value) []string {
+	if !structV.IsValid() {
+		return nil
+	}
+	var keys []string
+	for _, info := range cachedStructColumns(structV.Type()) {
+		if info.pk || info.autoIncr {
+			keys = append(keys, info.column)
+		}
+	}
+	return keys
+}
+
+func mapValues(values map[string]interface{}) ([]string, []interface{}) {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		args[i] = values[column]
+	}
+	return columns, args
+}
+
+func upsertSQL(table string, columns []string, updateColumns []string, syntax UpsertSyntax) string {
+	var sb strings.Builder
+	sb.WriteString(insertSQL(table, columns, 1))
+	if syntax == UpsertRowAlias {
+		sb.WriteString(" AS new")
+	}
+	sb.WriteString(" ON DUPLICATE KEY UPDATE ")
+	if len(updateColumns) == 0 {
+		// nothing to overwrite: a no-op assignment keeps the existing row and reports it unchanged
+		column := quoteIdentifier(columns[0])
+		sb.WriteString(column + " = " + column)
+		return sb.String()
+	}
+	for i, column := range updateColumns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		quoted := quoteIdentifier(column)
+		if syntax == UpsertRowAlias {
+			sb.WriteString(quoted + " = new." + quoted)
+		} else {
+			sb.WriteString(quoted + " = VALUES(" + quoted + ")")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package mysqlclient
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	columns := []string{"id", "name", "age"}
+	assert.EqualValues(t,
+		"INSERT INTO `userinfo` (`id`, `name`, `age`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`)",
+		upsertSQL("userinfo", columns, []string{"name", "age"}, UpsertValues))
+	assert.EqualValues(t,
+		"INSERT INTO `userinfo` (`id`, `name`, `age`) VALUES (?, ?, ?) AS new ON DUPLICATE KEY UPDATE `name` = new.`name`",
+		upsertSQL("userinfo", columns, []string{"name"}, UpsertRowAlias))
+	assert.EqualValues(t,
+		"INSERT INTO `userinfo` (`id`) VALUES (?) ON DUPLICATE KEY UPDATE `id` = `id`",
+		upsertSQL("userinfo", []string{"id"}, nil, UpsertValues))
+}
+
+func TestUpsertUpdateColumns(t *testing.T) {
+	user := reflect.ValueOf(&crudUser{Id: 1}).Elem()
+	columns := []string{"id", "name", "age"}
+	config := newUpsertConfig(nil)
+	assert.EqualValues(t, []string{"name", "age"}, config.update(columns, keyColumns(user)))
+	assert.EqualValues(t, columns, config.update(columns, keyColumns(reflect.Value{})))
+	config = newUpsertConfig([]UpsertOption{UpdateColumns("age"), Syntax(UpsertRowAlias)})
+	assert.EqualValues(t, []string{"age"}, config.update(columns, keyColumns(user)))
+	assert.EqualValues(t, UpsertRowAlias, config.syntax)
+}
+
+func TestMapValues(t *testing.T) {
+	columns, args := mapValues(map[string]interface{}{"name": "test name", "age": 21, "id": 1})
+	assert.EqualValues(t, []string{"age", "id", "name"}, columns)
+	assert.EqualValues(t, []interface{}{21, 1, "test name"}, args)
+}
+
+func TestUpsertBatch_NoColumns(t *testing.T) {
+	type note struct {
+		Id   int64  `db:"id,pk,autoincr"`
+		Note string `db:"note,omitempty"`
+	}
+	mc := &MysqlClient{}
+	_, err := mc.UpsertBatch("note", []*note{{}})
+	assert.NotNil(t, err)
+	_, err = mc.UpsertBatch("note", []map[string]interface{}{{}})
+	assert.NotNil(t, err)
+}
+
+func TestMysqlClient_Upsert(t *testing.T) {
+	once.Do(setup)
+	user := &crudUser{Name: "test name", Age: 21}
+	status, err := mysqlClient.Upsert("userinfo", user)
+	assert.Nil(t, err)
+	assert.EqualValues(t, UpsertInserted, status)
+	user.Age = 22
+	status, err = mysqlClient.Upsert("userinfo", user)
+	assert.Nil(t, err)
+	assert.EqualValues(t, UpsertUpdated, status)
+	statuses, err := mysqlClient.UpsertBatch("userinfo", []*crudUser{user})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []UpsertStatus{UpsertUnchanged}, statuses)
+}
@@ -1,4 +1,4 @@
-package dbclient
+package mysqlclient
 
 import (
 	"reflect"
@@ -39,3 +39,8 @@ func isSlice(input interface{}) bool {
 func isStructPtr(t reflect.Type) bool {
 	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
 }
+
+func isSlicePtr(input interface{}) bool {
+	inputT := reflect.TypeOf(input)
+	return inputT != nil && inputT.Kind() == reflect.Ptr && inputT.Elem().Kind() == reflect.Slice
+}
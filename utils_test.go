@@ -1,4 +1,4 @@
-package dbclient
+package mysqlclient
 
 import (
 	"github.com/stretchr/testify/assert"